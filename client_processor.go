@@ -9,6 +9,7 @@ package mirbft
 import (
 	"bytes"
 	"container/list"
+	"sort"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -16,6 +17,7 @@ import (
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 	"github.com/IBM/mirbft/pkg/pb/state"
 	"github.com/IBM/mirbft/pkg/statemachine"
+	"github.com/IBM/mirbft/pkg/status"
 )
 
 var ErrClientNotExist error = errors.New("client does not exist")
@@ -28,16 +30,120 @@ type RequestStore interface {
 	Sync() error
 }
 
+// RequestStoreBatch accumulates writes to a RequestStore, so that many
+// requests and allocations may be written together.  None of the writes
+// are visible until Write is invoked, at which point they are written
+// atomically.  Cancel discards the writes and releases the resources of
+// the batch, it must be invoked if Write is not, and has no effect after
+// Write.  A batch may not be used after Write or Cancel.
+type RequestStoreBatch interface {
+	PutAllocation(clientID, reqNo uint64, digest []byte) error
+	PutRequest(requestAck *msgs.RequestAck, data []byte) error
+	Write() error
+	Cancel()
+}
+
+// BatchRequestStore may optionally be implemented by a RequestStore which
+// supports batched writes.  When the RequestStore supplied to the
+// ClientProcessor implements it, the writes of each proposal (or batch of
+// proposals) are made with a single batch, rather than one by one.
+type BatchRequestStore interface {
+	NewBatch() RequestStoreBatch
+}
+
+// ClientRemovingRequestStore may optionally be implemented by a RequestStore
+// which supports deleting all of the state of a client.  When the RequestStore
+// supplied to the ClientProcessor implements it, the requests and allocations
+// of clients removed from the network are deleted once the removal checkpoints.
+type ClientRemovingRequestStore interface {
+	RemoveClient(clientID uint64) error
+}
+
+// GarbageCollectingRequestStore may optionally be implemented by a RequestStore
+// which supports deleting the requests and allocations of each client below the
+// client's low watermark.  When the RequestStore supplied to the ClientProcessor
+// implements it, it is invoked with the client states of the previous checkpoint
+// as each checkpoint is taken, leaving a checkpoint interval of committed
+// requests available to be forwarded to lagging replicas.
+type GarbageCollectingRequestStore interface {
+	GarbageCollect(clients []*msgs.NetworkState_Client) (int, error)
+}
+
+// requestWriter writes requests and allocations to a RequestStore, using
+// a batch when the store supports it.  Nothing is guaranteed to be written
+// until flush is invoked.
+type requestWriter struct {
+	requestStore RequestStore
+	batch        RequestStoreBatch
+}
+
+func newRequestWriter(requestStore RequestStore) *requestWriter {
+	rw := &requestWriter{
+		requestStore: requestStore,
+	}
+
+	if brs, ok := requestStore.(BatchRequestStore); ok {
+		rw.batch = brs.NewBatch()
+	}
+
+	return rw
+}
+
+func (rw *requestWriter) putRequest(ack *msgs.RequestAck, data []byte) error {
+	if rw.batch != nil {
+		return rw.batch.PutRequest(ack, data)
+	}
+	return rw.requestStore.PutRequest(ack, data)
+}
+
+func (rw *requestWriter) putAllocation(clientID, reqNo uint64, digest []byte) error {
+	if rw.batch != nil {
+		return rw.batch.PutAllocation(clientID, reqNo, digest)
+	}
+	return rw.requestStore.PutAllocation(clientID, reqNo, digest)
+}
+
+func (rw *requestWriter) flush() error {
+	if rw.batch == nil {
+		return nil
+	}
+	return rw.batch.Write()
+}
+
+// cancel releases the batch, if any, discarding the writes if flush was not
+// invoked.  It should be deferred once the writer is created.
+func (rw *requestWriter) cancel() {
+	if rw.batch != nil {
+		rw.batch.Cancel()
+	}
+}
+
 // ClientProcessor is the client half of the processor components.
 // It accepts client related actions from the state machine and injects
-// new client requests.
+// new client requests.  The Link is used to forward requests to other
+// replicas which are missing them, if no Link is set, requests are not
+// forwarded, which is only safe for a single node network.
 type ClientProcessor struct {
 	mutex        sync.Mutex
 	NodeID       uint64
 	RequestStore RequestStore
 	Hasher       Hasher
+	Link         Link
 	clients      map[uint64]*Client
 	ClientWork   ClientWork
+
+	// Logger, if set, is used to report requests which could not be
+	// forwarded.  If not set, ConsoleWarnLogger is used.
+	Logger Logger
+
+	// removedClients are clients which were removed from the network by
+	// the most recent checkpoint, they are removed on the next invocation of Process, as
+	// commits which precede the checkpoint may still be in flight.
+	removedClients []uint64
+
+	// lastCheckpointClients are the client states of the most recent
+	// checkpoint, which are garbage collected at the next checkpoint.
+	lastCheckpointClients []*msgs.NetworkState_Client
 }
 
 type ClientWork struct {
@@ -104,51 +210,68 @@ func (cp *ClientProcessor) Client(clientID uint64) *Client {
 func (cp *ClientProcessor) Process(actions *statemachine.ActionList) (*statemachine.EventList, error) {
 	events := &statemachine.EventList{}
 
+	if err := cp.removeClients(); err != nil {
+		return nil, err
+	}
+
 	iter := actions.Iterator()
 	for action := iter.Next(); action != nil; action = iter.Next() {
 		switch t := action.Type.(type) {
 		case *state.Action_AllocatedRequest:
 			r := t.AllocatedRequest
 			client := cp.Client(r.ClientId)
-			digest, err := client.allocate(r.ReqNo)
+			ack, err := client.allocate(r.ReqNo)
 			if err != nil {
 				return nil, err
 			}
 
-			if digest == nil {
+			if ack == nil {
 				continue
 			}
 
-			events.RequestPersisted(&msgs.RequestAck{
-				ClientId: r.ClientId,
-				ReqNo:    r.ReqNo,
-				Digest:   digest,
-			})
+			events.RequestPersisted(ack)
 		case *state.Action_ForwardRequest:
-		// XXX address
-		/*
-		   requestData, err := p.RequestStore.Get(r.RequestAck)
-		   if err != nil {
-		           panic(fmt.Sprintf("could not store request, unsafe to continue: %s\n", err))
-		   }
-
-		   fr := &msgs.Msg{
-		           Type: &msgs.Msg_ForwardRequest{
-		                   &msgs.ForwardRequest{
-		                           RequestAck:  r.RequestAck,
-		                           RequestData: requestData,
-		                   },
-		           },
-		   }
-		   for _, replica := range r.Targets {
-		           if replica == p.Node.Config.ID {
-		                   p.Node.Step(context.Background(), replica, fr)
-		           } else {
-		                   p.Link.Send(replica, fr)
-		           }
-		   }
-		*/
+			r := t.ForwardRequest
+			if cp.Link == nil {
+				cp.logger().Log(LevelWarn, "no link configured, not forwarding request", "client_id", r.Ack.ClientId, "req_no", r.Ack.ReqNo)
+				continue
+			}
+
+			requestData, err := cp.RequestStore.GetRequest(r.Ack)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "could not get request %d.%d for forwarding", r.Ack.ClientId, r.Ack.ReqNo)
+			}
+
+			if requestData == nil {
+				// We may have garbage collected this request since the
+				// state machine asked us to forward it, nothing to send.
+				continue
+			}
+
+			fr := &msgs.Msg{
+				Type: &msgs.Msg_ForwardRequest{
+					ForwardRequest: &msgs.ForwardRequest{
+						RequestAck:  r.Ack,
+						RequestData: requestData,
+					},
+				},
+			}
+
+			for _, replica := range r.Targets {
+				if replica == cp.NodeID {
+					// We already have the request
+					continue
+				}
+				cp.Link.Send(replica, fr)
+			}
 		case *state.Action_CorrectRequest:
+			ack := t.CorrectRequest
+			cp.Client(ack.ClientId).addCorrectDigest(ack.ReqNo, ack.Digest)
+		case *state.Action_Checkpoint:
+			if err := cp.garbageCollect(); err != nil {
+				return nil, err
+			}
+			cp.checkpointClients(t.Checkpoint.ClientStates)
 		default:
 			// Handled elsewhere... for now
 		}
@@ -161,6 +284,112 @@ func (cp *ClientProcessor) Process(actions *statemachine.ActionList) (*statemach
 	return events, nil
 }
 
+func (cp *ClientProcessor) logger() Logger {
+	if cp.Logger == nil {
+		return ConsoleWarnLogger
+	}
+	return cp.Logger
+}
+
+// checkpointClients records any client which was present in the previous
+// checkpointed client states, but is absent from these, for removal, and
+// retains the client states for garbage collection at the next checkpoint.
+// Clients which have never been checkpointed, such as those created by a
+// proposal for a client not yet added to the network, are not removed.
+func (cp *ClientProcessor) checkpointClients(clientStates []*msgs.NetworkState_Client) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	remaining := map[uint64]struct{}{}
+	for _, clientState := range clientStates {
+		remaining[clientState.Id] = struct{}{}
+	}
+
+	for _, clientState := range cp.lastCheckpointClients {
+		if _, ok := remaining[clientState.Id]; ok {
+			continue
+		}
+
+		cp.removedClients = append(cp.removedClients, clientState.Id)
+	}
+
+	cp.lastCheckpointClients = clientStates
+}
+
+// garbageCollect deletes the requests and allocations below the low watermarks
+// of the previous checkpoint, if the RequestStore supports it.
+func (cp *ClientProcessor) garbageCollect() error {
+	cp.mutex.Lock()
+	clientStates := cp.lastCheckpointClients
+	cp.mutex.Unlock()
+
+	gcrs, ok := cp.RequestStore.(GarbageCollectingRequestStore)
+	if !ok || clientStates == nil {
+		return nil
+	}
+
+	if _, err := gcrs.GarbageCollect(clientStates); err != nil {
+		return errors.WithMessage(err, "could not garbage collect request store")
+	}
+
+	return nil
+}
+
+// removeClients discards the clients recorded for removal, and, if the
+// RequestStore supports it, deletes their requests and allocations.
+func (cp *ClientProcessor) removeClients() error {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	crrs, ok := cp.RequestStore.(ClientRemovingRequestStore)
+
+	for _, clientID := range cp.removedClients {
+		delete(cp.clients, clientID)
+		if !ok {
+			continue
+		}
+
+		if err := crrs.RemoveClient(clientID); err != nil {
+			return errors.WithMessagef(err, "could not remove client %d from request store", clientID)
+		}
+	}
+
+	cp.removedClients = nil
+
+	return nil
+}
+
+// StepForwardRequest is the ingress path for ForwardRequest messages
+// received from other replicas.  These messages must not be stepped into
+// the state machine, but should instead be delivered here.  The digest of
+// the request data is verified against the request ack, and, if the state
+// machine has indicated that the digest is correct for this request, the
+// data is persisted to the request store and a RequestPersisted event is
+// made available via the ClientWork.  A non-nil error indicates the forwarded
+// request was rejected, and is not fatal to the processor.
+func (cp *ClientProcessor) StepForwardRequest(source uint64, fr *msgs.ForwardRequest) error {
+	ack := fr.RequestAck
+	if ack == nil {
+		return errors.Errorf("forwarded request from node %d has no request ack", source)
+	}
+
+	h := cp.Hasher.New()
+	h.Write(fr.RequestData)
+	digest := h.Sum(nil)
+	if !bytes.Equal(digest, ack.Digest) {
+		return errors.Errorf("forwarded request %d.%d from node %d has digest %x but data hashes to %x", ack.ClientId, ack.ReqNo, source, ack.Digest, digest)
+	}
+
+	cp.mutex.Lock()
+	client, ok := cp.clients[ack.ClientId]
+	cp.mutex.Unlock()
+	if !ok {
+		return errors.Errorf("forwarded request from node %d references unknown client %d", source, ack.ClientId)
+	}
+
+	return client.storeForwarded(ack, fr.RequestData)
+}
+
 type Client struct {
 	mutex        sync.Mutex
 	clientWork   *ClientWork
@@ -170,48 +399,217 @@ type Client struct {
 	requests     *list.List
 	reqNoMap     map[uint64]*list.Element
 	nextReqNo    uint64
+	allocated    bool
+
+	// correctDigests holds the known correct digests for request
+	// numbers which have not yet been allocated, they are merged into
+	// the request once it is allocated.
+	correctDigests map[uint64][][]byte
 }
 
 func newClient(clientID uint64, hasher Hasher, reqStore RequestStore, clientWork *ClientWork) *Client {
 	return &Client{
-		clientID:     clientID,
-		clientWork:   clientWork,
-		hasher:       hasher,
-		requestStore: reqStore,
-		requests:     list.New(),
-		reqNoMap:     map[uint64]*list.Element{},
+		clientID:       clientID,
+		clientWork:     clientWork,
+		hasher:         hasher,
+		requestStore:   reqStore,
+		requests:       list.New(),
+		reqNoMap:       map[uint64]*list.Element{},
+		correctDigests: map[uint64][][]byte{},
 	}
 }
 
 type clientRequest struct {
 	reqNo                 uint64
 	localAllocationDigest []byte
+	localAllocationSize   uint32
 	remoteCorrectDigests  [][]byte
+	null                  bool // set when a null request was injected for this reqNo
+	allocated             bool // set once the state machine allocates this reqNo
 }
 
-func (c *Client) allocate(reqNo uint64) ([]byte, error) {
+// allocate is invoked as the state machine allocates each request number,
+// in order.  The first allocation is at the client's low watermark, so when
+// restarting, the next request number is fast-forwarded to it, and then past
+// any requests which were stored before the restart.  If a request has been
+// stored for the request number, its ack is returned, otherwise nil.
+func (c *Client) allocate(reqNo uint64) (*msgs.RequestAck, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
+
+	if !c.allocated {
+		c.allocated = true
+		if reqNo > c.nextReqNo {
+			c.nextReqNo = reqNo
+		}
+	}
+
 	el, ok := c.reqNoMap[reqNo]
 	if ok {
-		clientReq := el.Value.(*clientRequest)
-		return clientReq.localAllocationDigest, nil
+		cr := el.Value.(*clientRequest)
+		cr.allocated = true
+		c.mergeCorrectDigests(cr)
+		return c.localAck(cr), nil
 	}
 
 	cr := &clientRequest{
-		reqNo: reqNo,
+		reqNo:     reqNo,
+		allocated: true,
 	}
 	el = c.requests.PushBack(cr)
 	c.reqNoMap[reqNo] = el
+	c.mergeCorrectDigests(cr)
 
 	digest, err := c.requestStore.GetAllocation(c.clientID, reqNo)
 	if err != nil {
 		return nil, errors.WithMessagef(err, "could not get key for %d.%d", c.clientID, reqNo)
 	}
 
+	if digest == nil {
+		return nil, nil
+	}
+
+	data, err := c.requestStore.GetRequest(&msgs.RequestAck{
+		ClientId: c.clientID,
+		ReqNo:    reqNo,
+		Digest:   digest,
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "could not get request %d.%d", c.clientID, reqNo)
+	}
+
 	cr.localAllocationDigest = digest
+	cr.localAllocationSize = uint32(len(data))
+
+	if reqNo == c.nextReqNo {
+		c.nextReqNo++
+	}
+
+	return c.localAck(cr), nil
+}
+
+// localAck returns the ack for the locally stored request, or nil if
+// no request is stored.  The caller must hold the mutex.
+func (c *Client) localAck(cr *clientRequest) *msgs.RequestAck {
+	if cr.localAllocationDigest == nil {
+		return nil
+	}
 
-	return digest, nil
+	return &msgs.RequestAck{
+		ClientId: c.clientID,
+		ReqNo:    cr.reqNo,
+		Digest:   cr.localAllocationDigest,
+		Size:     cr.localAllocationSize,
+	}
+}
+
+// addCorrectDigest records that a weak quorum of replicas has acknowledged
+// the digest for this request number, so at least one correct replica has it.
+// If the request number has not been allocated, the digest is held until it
+// is, so that the correct digest alone does not make the request number
+// appear allocated.
+func (c *Client) addCorrectDigest(reqNo uint64, digest []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	el, ok := c.reqNoMap[reqNo]
+	if !ok || !el.Value.(*clientRequest).allocated {
+		if !containsDigest(c.correctDigests[reqNo], digest) {
+			c.correctDigests[reqNo] = append(c.correctDigests[reqNo], digest)
+		}
+		return
+	}
+
+	cr := el.Value.(*clientRequest)
+	if cr.isCorrect(digest) {
+		return
+	}
+
+	cr.remoteCorrectDigests = append(cr.remoteCorrectDigests, digest)
+}
+
+// mergeCorrectDigests adds any correct digests which were recorded before
+// the request number was allocated to the request.  The caller must hold
+// the mutex.
+func (c *Client) mergeCorrectDigests(cr *clientRequest) {
+	for _, digest := range c.correctDigests[cr.reqNo] {
+		if !cr.isCorrect(digest) {
+			cr.remoteCorrectDigests = append(cr.remoteCorrectDigests, digest)
+		}
+	}
+	delete(c.correctDigests, cr.reqNo)
+}
+
+func (cr *clientRequest) isCorrect(digest []byte) bool {
+	return containsDigest(cr.remoteCorrectDigests, digest)
+}
+
+func containsDigest(digests [][]byte, digest []byte) bool {
+	for _, d := range digests {
+		if bytes.Equal(d, digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// storeForwarded persists the data for a request supplied by another replica.
+// The digest of the data must already have been verified against the ack, and
+// the data is only accepted if the digest is known to be correct.
+func (c *Client) storeForwarded(ack *msgs.RequestAck, data []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	el, ok := c.reqNoMap[ack.ReqNo]
+	if !ok {
+		return errors.Errorf("forwarded request %d.%d is not allocated", c.clientID, ack.ReqNo)
+	}
+
+	cr := el.Value.(*clientRequest)
+	if !cr.isCorrect(ack.Digest) {
+		return errors.Errorf("forwarded request %d.%d has digest %x which is not known to be correct", c.clientID, ack.ReqNo, ack.Digest)
+	}
+
+	if bytes.Equal(cr.localAllocationDigest, ack.Digest) {
+		// We already have this request
+		return nil
+	}
+
+	// The size is not covered by the digest, so rather than trust
+	// the size supplied by the other replica, compute our own.
+	ack = &msgs.RequestAck{
+		ClientId: ack.ClientId,
+		ReqNo:    ack.ReqNo,
+		Digest:   ack.Digest,
+		Size:     uint32(len(data)),
+	}
+
+	writer := newRequestWriter(c.requestStore)
+	defer writer.cancel()
+
+	err := writer.putRequest(ack, data)
+	if err != nil {
+		return errors.WithMessage(err, "could not store forwarded request")
+	}
+
+	allocate := cr.localAllocationDigest == nil
+	if allocate {
+		err = writer.putAllocation(c.clientID, ack.ReqNo, ack.Digest)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.flush(); err != nil {
+		return errors.WithMessage(err, "could not store forwarded request")
+	}
+
+	if allocate {
+		cr.localAllocationDigest = ack.Digest
+		cr.localAllocationSize = ack.Size
+	}
+
+	c.clientWork.addPersistedReq(ack)
+
+	return nil
 }
 
 func (c *Client) NextReqNo() (uint64, error) {
@@ -224,81 +622,283 @@ func (c *Client) NextReqNo() (uint64, error) {
 	return c.nextReqNo, nil
 }
 
-func (c *Client) Propose(reqNo uint64, data []byte) error {
-	h := c.hasher.New()
-	h.Write(data)
-	digest := h.Sum(nil)
+// Recover resynchronizes the client after the client crashes, using the
+// network's view of the client.  The views are the results of
+// Node.ClientStatus at distinct replicas, and there must be at least f+1 of
+// them, so that at least one is from a correct replica.  Only what at least
+// f+1 of the views agree on is trusted: the low watermark, the high watermark,
+// and the furthest request number for which some replica has acked a request,
+// past which the next request number is fast-forwarded.  Because the client
+// may no longer have the requests for the uncommitted request numbers it
+// skips, a null request is injected for each one which fewer than f+1 views
+// report as acked or committed, and which this replica has not stored, so
+// that the client's window may advance.  Request numbers which f+1 views
+// report as acked are not filled, as the acked request may still commit.  Null
+// requests are not persisted to the request store, so if the replica
+// restarts, Recover should be invoked again.  The new next request number is
+// returned.
+func (c *Client) Recover(clientStatuses []*status.ClientTracker, f int) (uint64, error) {
+	if len(clientStatuses) < f+1 {
+		return 0, errors.Errorf("recovery requires at least %d client statuses, got %d", f+1, len(clientStatuses))
+	}
+
+	resumeReqNos := make([]uint64, len(clientStatuses))
+	lowWatermarks := make([]uint64, len(clientStatuses))
+	highWatermarks := make([]uint64, len(clientStatuses))
+	for i, clientStatus := range clientStatuses {
+		if clientStatus.ClientID != c.clientID {
+			return 0, errors.Errorf("status is for client %d, not client %d", clientStatus.ClientID, c.clientID)
+		}
+
+		resumeReqNos[i] = clientStatus.LowWatermark
+		for j, reqStatus := range clientStatus.Allocated {
+			if reqStatus != status.ClientReqUnknown {
+				resumeReqNos[i] = clientStatus.LowWatermark + uint64(j) + 1
+			}
+		}
+
+		lowWatermarks[i] = clientStatus.LowWatermark
+		highWatermarks[i] = clientStatus.HighWatermark
+	}
+
+	// Only trust a resume point or window which at least f+1 views,
+	// and therefore at least one correct replica, agree on.
+	resumeReqNo := orderStatistic(resumeReqNos, f)
+	lowWatermark := orderStatistic(lowWatermarks, f)
+	highWatermark := orderStatistic(highWatermarks, f)
 
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	if c.requests.Len() == 0 {
-		return ErrClientNotExist
+
+	if c.nextReqNo > resumeReqNo {
+		resumeReqNo = c.nextReqNo
 	}
 
-	if reqNo < c.nextReqNo {
-		return nil
+	if resumeReqNo > highWatermark+1 {
+		resumeReqNo = highWatermark + 1
+	}
+
+	for reqNo := lowWatermark; reqNo < resumeReqNo; reqNo++ {
+		if knownReqNo(clientStatuses, reqNo, f) {
+			continue
+		}
+
+		el, ok := c.reqNoMap[reqNo]
+		if !ok {
+			el = c.requests.PushBack(&clientRequest{
+				reqNo: reqNo,
+			})
+			c.reqNoMap[reqNo] = el
+		}
+
+		cr := el.Value.(*clientRequest)
+		if cr.localAllocationDigest != nil {
+			continue
+		}
+
+		c.proposeNull(cr)
 	}
 
-	if reqNo > c.nextReqNo {
-		return errors.Errorf("client must submit req_no %d next", c.nextReqNo)
+	if resumeReqNo > c.nextReqNo {
+		c.nextReqNo = resumeReqNo
 	}
 
-	c.nextReqNo++
+	return c.nextReqNo, nil
+}
+
+// orderStatistic returns the f+1-th largest of the values, the largest value
+// which at least f+1 of them are greater than or equal to.
+func orderStatistic(values []uint64, f int) uint64 {
+	sorted := append([]uint64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+	return sorted[f]
+}
+
+// knownReqNo returns whether at least f+1 of the views report a request as
+// acked or committed for the given request number.
+func knownReqNo(clientStatuses []*status.ClientTracker, reqNo uint64, f int) bool {
+	known := 0
+	for _, clientStatus := range clientStatuses {
+		if reqNo < clientStatus.LowWatermark {
+			known++
+			continue
+		}
+
+		offset := reqNo - clientStatus.LowWatermark
+		if offset < uint64(len(clientStatus.Allocated)) && clientStatus.Allocated[offset] != status.ClientReqUnknown {
+			known++
+		}
+	}
+
+	return known > f
+}
+
+// ProposeNull injects a null request, a request with no data, for the given
+// request number.  The null request is acked to the network like any other
+// request, and once a quorum acks it, it may be committed in place of any
+// other request for the request number.  This allows a client whose window
+// is stuck behind a request which cannot commit (for instance, because the
+// client crashed after sending it to too few replicas) to flush the window
+// past the gap.  To commit, the null request must be proposed at enough
+// replicas for it to become known correct, after which the remaining
+// correct replicas adopt it themselves.  Proposing a null request for
+// a request number which already has one is a no-op.
+func (c *Client) ProposeNull(reqNo uint64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.requests.Len() == 0 {
+		return ErrClientNotExist
+	}
 
 	el, ok := c.reqNoMap[reqNo]
-	previouslyAllocated := ok
 	if !ok {
-		// TODO, limit the distance ahead a client can allocate?
 		el = c.requests.PushBack(&clientRequest{
 			reqNo: reqNo,
 		})
 		c.reqNoMap[reqNo] = el
 	}
 
-	cr := el.Value.(*clientRequest)
+	c.proposeNull(el.Value.(*clientRequest))
 
-	if cr.localAllocationDigest != nil {
-		if bytes.Equal(cr.localAllocationDigest, digest) {
-			return nil
-		}
-
-		return errors.Errorf("cannot store request with digest %x, already stored request with different digest %x", digest, cr.localAllocationDigest)
+	if reqNo == c.nextReqNo {
+		c.nextReqNo++
 	}
 
-	if len(cr.remoteCorrectDigests) > 0 {
-		found := false
-		for _, rd := range cr.remoteCorrectDigests {
-			if bytes.Equal(rd, digest) {
-				found = true
-				break
-			}
-		}
+	return nil
+}
 
-		if !found {
-			return errors.New("other known correct digest exist for reqno")
-		}
+// proposeNull marks the request as null and makes the null request
+// available to the state machine.  The caller must hold the mutex.
+func (c *Client) proposeNull(cr *clientRequest) {
+	if cr.null {
+		return
 	}
 
-	ack := &msgs.RequestAck{
+	cr.null = true
+	c.clientWork.addPersistedReq(&msgs.RequestAck{
 		ClientId: c.clientID,
-		ReqNo:    reqNo,
-		Digest:   digest,
+		ReqNo:    cr.reqNo,
+	})
+}
+
+func (c *Client) Propose(reqNo uint64, data []byte) error {
+	return c.ProposeBatch(reqNo, [][]byte{data})
+}
+
+// ProposeBatch proposes a sequence of requests with consecutive request
+// numbers, beginning with reqNo.  The requests are persisted with a single
+// batch write when the RequestStore supports it.  As with Propose, requests
+// with request numbers which have already been proposed are ignored.
+func (c *Client) ProposeBatch(reqNo uint64, data [][]byte) error {
+	digests := make([][]byte, len(data))
+	for i, d := range data {
+		h := c.hasher.New()
+		h.Write(d)
+		digests[i] = h.Sum(nil)
 	}
 
-	err := c.requestStore.PutRequest(ack, data)
-	if err != nil {
-		return errors.WithMessage(err, "could not store requests")
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.requests.Len() == 0 {
+		return ErrClientNotExist
 	}
 
-	err = c.requestStore.PutAllocation(c.clientID, reqNo, digest)
-	if err != nil {
+	writer := newRequestWriter(c.requestStore)
+	defer writer.cancel()
+
+	type pendingRequest struct {
+		cr                  *clientRequest
+		ack                 *msgs.RequestAck
+		previouslyAllocated bool
+	}
+	var pending []pendingRequest
+
+	// complete flushes the writer, and once the writes are durable,
+	// updates the state of the requests which were written.
+	complete := func(err error) error {
+		if flushErr := writer.flush(); flushErr != nil {
+			return errors.WithMessage(flushErr, "could not store requests")
+		}
+
+		for _, p := range pending {
+			p.cr.localAllocationDigest = p.ack.Digest
+			p.cr.localAllocationSize = p.ack.Size
+			if p.previouslyAllocated {
+				c.clientWork.addPersistedReq(p.ack)
+			}
+		}
+
 		return err
 	}
-	cr.localAllocationDigest = digest
 
-	if previouslyAllocated {
-		c.clientWork.addPersistedReq(ack)
+	for i, digest := range digests {
+		reqNo := reqNo + uint64(i)
+
+		if reqNo < c.nextReqNo {
+			continue
+		}
+
+		if reqNo > c.nextReqNo {
+			return complete(errors.Errorf("client must submit req_no %d next", c.nextReqNo))
+		}
+
+		c.nextReqNo++
+
+		el, ok := c.reqNoMap[reqNo]
+		if !ok {
+			// TODO, limit the distance ahead a client can allocate?
+			el = c.requests.PushBack(&clientRequest{
+				reqNo: reqNo,
+			})
+			c.reqNoMap[reqNo] = el
+		}
+
+		cr := el.Value.(*clientRequest)
+		previouslyAllocated := cr.allocated
+
+		if cr.localAllocationDigest != nil {
+			if bytes.Equal(cr.localAllocationDigest, digest) {
+				continue
+			}
+
+			return complete(errors.Errorf("cannot store request with digest %x, already stored request with different digest %x", digest, cr.localAllocationDigest))
+		}
+
+		if cr.null {
+			return complete(errors.Errorf("a null request was proposed for req_no %d", reqNo))
+		}
+
+		if len(cr.remoteCorrectDigests) > 0 && !cr.isCorrect(digest) {
+			return complete(errors.New("other known correct digest exist for reqno"))
+		}
+
+		if correct := c.correctDigests[reqNo]; len(correct) > 0 && !containsDigest(correct, digest) {
+			return complete(errors.New("other known correct digest exist for reqno"))
+		}
+
+		ack := &msgs.RequestAck{
+			ClientId: c.clientID,
+			ReqNo:    reqNo,
+			Digest:   digest,
+			Size:     uint32(len(data[i])),
+		}
+
+		err := writer.putRequest(ack, data[i])
+		if err != nil {
+			return complete(errors.WithMessage(err, "could not store requests"))
+		}
+
+		err = writer.putAllocation(c.clientID, reqNo, digest)
+		if err != nil {
+			return complete(err)
+		}
+
+		pending = append(pending, pendingRequest{
+			cr:                  cr,
+			ack:                 ack,
+			previouslyAllocated: previouslyAllocated,
+		})
 	}
 
-	return nil
+	return complete(nil)
 }
@@ -0,0 +1,680 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirbft_test
+
+import (
+	"crypto"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/pb/state"
+	"github.com/IBM/mirbft/pkg/reqstore"
+	"github.com/IBM/mirbft/pkg/statemachine"
+	"github.com/IBM/mirbft/pkg/status"
+)
+
+type DestMsg struct {
+	Dest uint64
+	Msg  *msgs.Msg
+}
+
+type RecordingLink struct {
+	Sent []DestMsg
+}
+
+func (rl *RecordingLink) Send(dest uint64, msg *msgs.Msg) {
+	rl.Sent = append(rl.Sent, DestMsg{
+		Dest: dest,
+		Msg:  msg,
+	})
+}
+
+type LogEntry struct {
+	Level mirbft.LogLevel
+	Text  string
+}
+
+type RecordingLogger struct {
+	Entries []LogEntry
+}
+
+func (rl *RecordingLogger) Log(level mirbft.LogLevel, text string, args ...interface{}) {
+	rl.Entries = append(rl.Entries, LogEntry{
+		Level: level,
+		Text:  text,
+	})
+}
+
+// CountingStore records how the ClientProcessor writes to the store.
+type CountingStore struct {
+	*reqstore.Store
+	Batches    int
+	DirectPuts int
+}
+
+func (cs *CountingStore) NewBatch() mirbft.RequestStoreBatch {
+	cs.Batches++
+	return cs.Store.NewBatch()
+}
+
+func (cs *CountingStore) PutRequest(ack *msgs.RequestAck, data []byte) error {
+	cs.DirectPuts++
+	return cs.Store.PutRequest(ack, data)
+}
+
+func sha256Digest(data []byte) []byte {
+	h := crypto.SHA256.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+var _ = Describe("ClientProcessor", func() {
+	var (
+		reqStore        *reqstore.Store
+		link            *RecordingLink
+		clientProcessor *mirbft.ClientProcessor
+		data            []byte
+		ack             *msgs.RequestAck
+	)
+
+	BeforeEach(func() {
+		var err error
+		reqStore, err = reqstore.Open("")
+		Expect(err).NotTo(HaveOccurred())
+
+		link = &RecordingLink{}
+
+		clientProcessor = &mirbft.ClientProcessor{
+			NodeID:       1,
+			RequestStore: reqStore,
+			Hasher:       crypto.SHA256,
+			Link:         link,
+		}
+
+		data = []byte("request-data")
+		ack = &msgs.RequestAck{
+			ClientId: 3,
+			ReqNo:    0,
+			Digest:   sha256Digest(data),
+		}
+
+		_, err = clientProcessor.Process((&statemachine.ActionList{}).AllocateRequest(3, 0))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		reqStore.Close()
+	})
+
+	Describe("ForwardRequest actions", func() {
+		BeforeEach(func() {
+			err := clientProcessor.Client(3).Propose(0, data)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("sends the stored request to every target but itself", func() {
+			_, err := clientProcessor.Process((&statemachine.ActionList{}).ForwardRequest([]uint64{0, 1, 2}, ack))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Sent).To(HaveLen(2))
+			Expect(link.Sent[0].Dest).To(Equal(uint64(0)))
+			Expect(link.Sent[1].Dest).To(Equal(uint64(2)))
+
+			fr := link.Sent[0].Msg.Type.(*msgs.Msg_ForwardRequest).ForwardRequest
+			Expect(fr.RequestAck).To(Equal(ack))
+			Expect(fr.RequestData).To(Equal(data))
+		})
+
+		When("the request is not in the store", func() {
+			It("sends nothing", func() {
+				otherAck := &msgs.RequestAck{
+					ClientId: 3,
+					ReqNo:    0,
+					Digest:   []byte("other-digest"),
+				}
+				_, err := clientProcessor.Process((&statemachine.ActionList{}).ForwardRequest([]uint64{0}, otherAck))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link.Sent).To(BeEmpty())
+			})
+		})
+
+		When("no link is configured", func() {
+			var logger *RecordingLogger
+
+			BeforeEach(func() {
+				logger = &RecordingLogger{}
+				clientProcessor.Link = nil
+				clientProcessor.Logger = logger
+			})
+
+			It("skips forwarding and logs a warning", func() {
+				_, err := clientProcessor.Process((&statemachine.ActionList{}).ForwardRequest([]uint64{0, 2}, ack))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link.Sent).To(BeEmpty())
+				Expect(logger.Entries).To(Equal([]LogEntry{
+					{
+						Level: mirbft.LevelWarn,
+						Text:  "no link configured, not forwarding request",
+					},
+				}))
+			})
+		})
+	})
+
+	Describe("StepForwardRequest", func() {
+		BeforeEach(func() {
+			_, err := clientProcessor.Process((&statemachine.ActionList{}).CorrectRequest(ack))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("persists correct requests whose data matches the digest", func() {
+			err := clientProcessor.StepForwardRequest(2, &msgs.ForwardRequest{
+				RequestAck:  ack,
+				RequestData: data,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			stored, err := reqStore.GetRequest(ack)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored).To(Equal(data))
+
+			Eventually(clientProcessor.ClientWork.Ready()).Should(BeClosed())
+			results := clientProcessor.ClientWork.Results()
+			Expect(results.Len()).To(Equal(1))
+			persisted := results.Iterator().Next().Type.(*state.Event_RequestPersisted).RequestPersisted
+			Expect(persisted.RequestAck).To(Equal(&msgs.RequestAck{
+				ClientId: ack.ClientId,
+				ReqNo:    ack.ReqNo,
+				Digest:   ack.Digest,
+				Size:     uint32(len(data)),
+			}))
+		})
+
+		It("rejects requests whose digest is not known to be correct", func() {
+			otherData := []byte("other-data")
+			err := clientProcessor.StepForwardRequest(2, &msgs.ForwardRequest{
+				RequestAck: &msgs.RequestAck{
+					ClientId: 3,
+					ReqNo:    0,
+					Digest:   sha256Digest(otherData),
+				},
+				RequestData: otherData,
+			})
+			Expect(err).To(MatchError(ContainSubstring("which is not known to be correct")))
+		})
+
+		It("refuses local proposals which conflict with the correct digest", func() {
+			err := clientProcessor.Client(3).Propose(0, []byte("other-data"))
+			Expect(err).To(MatchError("other known correct digest exist for reqno"))
+		})
+
+		It("rejects requests whose data does not match the digest", func() {
+			err := clientProcessor.StepForwardRequest(2, &msgs.ForwardRequest{
+				RequestAck:  ack,
+				RequestData: []byte("tampered"),
+			})
+			Expect(err).To(MatchError(ContainSubstring("but data hashes to")))
+
+			stored, err := reqStore.GetRequest(ack)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored).To(BeNil())
+		})
+
+		It("rejects requests which are not allocated", func() {
+			otherData := []byte("other-data")
+			err := clientProcessor.StepForwardRequest(2, &msgs.ForwardRequest{
+				RequestAck: &msgs.RequestAck{
+					ClientId: 3,
+					ReqNo:    7,
+					Digest:   sha256Digest(otherData),
+				},
+				RequestData: otherData,
+			})
+			Expect(err).To(MatchError("forwarded request 3.7 is not allocated"))
+		})
+	})
+
+	Describe("ProposeBatch", func() {
+		var countingStore *CountingStore
+
+		BeforeEach(func() {
+			countingStore = &CountingStore{Store: reqStore}
+			clientProcessor.RequestStore = countingStore
+
+			actions := (&statemachine.ActionList{}).
+				AllocateRequest(4, 0).
+				AllocateRequest(4, 1).
+				AllocateRequest(4, 2)
+			_, err := clientProcessor.Process(actions)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("persists all requests with a single batch", func() {
+			batch := [][]byte{[]byte("zero"), []byte("one"), []byte("two")}
+			err := clientProcessor.Client(4).ProposeBatch(0, batch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(countingStore.Batches).To(Equal(1))
+			Expect(countingStore.DirectPuts).To(Equal(0))
+
+			for i, data := range batch {
+				digest, err := reqStore.GetAllocation(4, uint64(i))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(digest).To(Equal(sha256Digest(data)))
+
+				stored, err := reqStore.GetRequest(&msgs.RequestAck{
+					ClientId: 4,
+					ReqNo:    uint64(i),
+					Digest:   digest,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stored).To(Equal(data))
+			}
+
+			Eventually(clientProcessor.ClientWork.Ready()).Should(BeClosed())
+			Expect(clientProcessor.ClientWork.Results().Len()).To(Equal(3))
+
+			nextReqNo, err := clientProcessor.Client(4).NextReqNo()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nextReqNo).To(Equal(uint64(3)))
+		})
+
+		It("persists the requests preceding an invalid request", func() {
+			err := clientProcessor.Client(4).ProposeBatch(1, [][]byte{[]byte("one")})
+			Expect(err).To(MatchError("client must submit req_no 0 next"))
+
+			_, err = clientProcessor.Process((&statemachine.ActionList{}).CorrectRequest(&msgs.RequestAck{
+				ClientId: 4,
+				ReqNo:    1,
+				Digest:   sha256Digest([]byte("one")),
+			}))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = clientProcessor.Client(4).ProposeBatch(0, [][]byte{[]byte("zero"), []byte("bogus")})
+			Expect(err).To(MatchError("other known correct digest exist for reqno"))
+
+			digest, err := reqStore.GetAllocation(4, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(Equal(sha256Digest([]byte("zero"))))
+
+			digest, err = reqStore.GetAllocation(4, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(BeNil())
+		})
+	})
+
+	Describe("CorrectRequest actions", func() {
+		var (
+			oneData   []byte
+			oneAck    *msgs.RequestAck
+			persisted func() []*msgs.RequestAck
+		)
+
+		BeforeEach(func() {
+			oneData = []byte("one")
+			oneAck = &msgs.RequestAck{
+				ClientId: 3,
+				ReqNo:    1,
+				Digest:   sha256Digest(oneData),
+				Size:     uint32(len(oneData)),
+			}
+
+			persisted = func() []*msgs.RequestAck {
+				var acks []*msgs.RequestAck
+				select {
+				case <-clientProcessor.ClientWork.Ready():
+				default:
+					return nil
+				}
+				iter := clientProcessor.ClientWork.Results().Iterator()
+				for event := iter.Next(); event != nil; event = iter.Next() {
+					acks = append(acks, event.Type.(*state.Event_RequestPersisted).RequestPersisted.RequestAck)
+				}
+				return acks
+			}
+
+			_, err := clientProcessor.Process((&statemachine.ActionList{}).CorrectRequest(&msgs.RequestAck{
+				ClientId: 3,
+				ReqNo:    1,
+				Digest:   oneAck.Digest,
+			}))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does not make unknown clients appear to exist", func() {
+			_, err := clientProcessor.Process((&statemachine.ActionList{}).CorrectRequest(&msgs.RequestAck{
+				ClientId: 9,
+				ReqNo:    0,
+				Digest:   oneAck.Digest,
+			}))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = clientProcessor.Client(9).NextReqNo()
+			Expect(err).To(Equal(mirbft.ErrClientNotExist))
+		})
+
+		It("does not treat the request number as allocated", func() {
+			err := clientProcessor.Client(3).ProposeBatch(0, [][]byte{data, oneData})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(persisted()).To(Equal([]*msgs.RequestAck{
+				{
+					ClientId: 3,
+					ReqNo:    0,
+					Digest:   ack.Digest,
+					Size:     uint32(len(data)),
+				},
+			}))
+
+			events, err := clientProcessor.Process((&statemachine.ActionList{}).AllocateRequest(3, 1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events.Len()).To(Equal(1))
+			Expect(events.Iterator().Next().Type.(*state.Event_RequestPersisted).RequestPersisted.RequestAck).To(Equal(oneAck))
+		})
+
+		It("refuses conflicting local proposals before the request number is allocated", func() {
+			err := clientProcessor.Client(3).ProposeBatch(0, [][]byte{data, []byte("other-data")})
+			Expect(err).To(MatchError("other known correct digest exist for reqno"))
+		})
+
+		It("accepts forwarded requests once the request number is allocated", func() {
+			_, err := clientProcessor.Process((&statemachine.ActionList{}).AllocateRequest(3, 1))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = clientProcessor.StepForwardRequest(2, &msgs.ForwardRequest{
+				RequestAck:  oneAck,
+				RequestData: oneData,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Recover", func() {
+		BeforeEach(func() {
+			err := reqStore.PutAllocation(5, 10, sha256Digest([]byte("ten")))
+			Expect(err).NotTo(HaveOccurred())
+
+			actions := (&statemachine.ActionList{}).
+				AllocateRequest(5, 10).
+				AllocateRequest(5, 11).
+				AllocateRequest(5, 12).
+				AllocateRequest(5, 13).
+				AllocateRequest(5, 14)
+			_, err = clientProcessor.Process(actions)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fast-forwards past the low watermark and stored requests on allocation", func() {
+			nextReqNo, err := clientProcessor.Client(5).NextReqNo()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nextReqNo).To(Equal(uint64(11)))
+		})
+
+		It("resumes after the last acked request and fills the unknown gaps with null requests", func() {
+			clientStatus := &status.ClientTracker{
+				ClientID:      5,
+				LowWatermark:  10,
+				HighWatermark: 14,
+				Allocated: []uint64{
+					status.ClientReqAcked,
+					status.ClientReqCommitted,
+					status.ClientReqUnknown,
+					status.ClientReqAcked,
+				},
+			}
+			clientStatuses := []*status.ClientTracker{clientStatus, clientStatus}
+			nextReqNo, err := clientProcessor.Client(5).Recover(clientStatuses, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nextReqNo).To(Equal(uint64(14)))
+
+			Eventually(clientProcessor.ClientWork.Ready()).Should(BeClosed())
+			results := clientProcessor.ClientWork.Results()
+			var nullReqNos []uint64
+			iter := results.Iterator()
+			for event := iter.Next(); event != nil; event = iter.Next() {
+				persisted := event.Type.(*state.Event_RequestPersisted).RequestPersisted
+				Expect(persisted.RequestAck.Digest).To(BeEmpty())
+				nullReqNos = append(nullReqNos, persisted.RequestAck.ReqNo)
+			}
+			Expect(nullReqNos).To(Equal([]uint64{12}))
+
+			By("ignoring proposals for the skipped request numbers")
+			Expect(clientProcessor.Client(5).Propose(13, []byte("thirteen"))).To(Succeed())
+			digest, err := reqStore.GetAllocation(5, 13)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(BeNil())
+
+			By("not injecting the null requests twice")
+			nextReqNo, err = clientProcessor.Client(5).Recover(clientStatuses, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nextReqNo).To(Equal(uint64(14)))
+			Consistently(clientProcessor.ClientWork.Ready(), 20*time.Millisecond).ShouldNot(BeClosed())
+		})
+
+		It("only trusts the low watermark and acks which f+1 views agree on", func() {
+			nextReqNo, err := clientProcessor.Client(5).Recover([]*status.ClientTracker{
+				{
+					ClientID:      5,
+					LowWatermark:  10,
+					HighWatermark: 14,
+					Allocated: []uint64{
+						status.ClientReqUnknown,
+						status.ClientReqAcked,
+						status.ClientReqUnknown,
+						status.ClientReqAcked,
+					},
+				},
+				{
+					ClientID:      5,
+					LowWatermark:  10,
+					HighWatermark: 14,
+					Allocated: []uint64{
+						status.ClientReqUnknown,
+						status.ClientReqUnknown,
+						status.ClientReqUnknown,
+						status.ClientReqAcked,
+					},
+				},
+				{
+					// A faulty view, claiming requests committed
+					ClientID:      5,
+					LowWatermark:  13,
+					HighWatermark: 14,
+					Allocated: []uint64{
+						status.ClientReqAcked,
+					},
+				},
+			}, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nextReqNo).To(Equal(uint64(14)))
+
+			Eventually(clientProcessor.ClientWork.Ready()).Should(BeClosed())
+			results := clientProcessor.ClientWork.Results()
+			var nullReqNos []uint64
+			iter := results.Iterator()
+			for event := iter.Next(); event != nil; event = iter.Next() {
+				persisted := event.Type.(*state.Event_RequestPersisted).RequestPersisted
+				nullReqNos = append(nullReqNos, persisted.RequestAck.ReqNo)
+			}
+			Expect(nullReqNos).To(Equal([]uint64{12}))
+		})
+
+		It("only resumes as far as f+1 views agree", func() {
+			nextReqNo, err := clientProcessor.Client(5).Recover([]*status.ClientTracker{
+				{
+					ClientID:      5,
+					LowWatermark:  10,
+					HighWatermark: 14,
+					Allocated: []uint64{
+						status.ClientReqAcked,
+						status.ClientReqAcked,
+						status.ClientReqUnknown,
+						status.ClientReqUnknown,
+						status.ClientReqAcked,
+					},
+				},
+				{
+					ClientID:      5,
+					LowWatermark:  10,
+					HighWatermark: 14,
+					Allocated: []uint64{
+						status.ClientReqAcked,
+						status.ClientReqAcked,
+					},
+				},
+			}, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nextReqNo).To(Equal(uint64(12)))
+			Consistently(clientProcessor.ClientWork.Ready(), 20*time.Millisecond).ShouldNot(BeClosed())
+		})
+
+		It("does not resume beyond the allocated window", func() {
+			nextReqNo, err := clientProcessor.Client(5).Recover([]*status.ClientTracker{{
+				ClientID:      5,
+				LowWatermark:  20,
+				HighWatermark: 24,
+			}}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nextReqNo).To(Equal(uint64(20)))
+		})
+
+		It("requires at least f+1 views", func() {
+			_, err := clientProcessor.Client(5).Recover([]*status.ClientTracker{{ClientID: 5}}, 1)
+			Expect(err).To(MatchError("recovery requires at least 2 client statuses, got 1"))
+		})
+
+		It("rejects the status of another client", func() {
+			_, err := clientProcessor.Client(5).Recover([]*status.ClientTracker{{ClientID: 6}}, 0)
+			Expect(err).To(MatchError("status is for client 6, not client 5"))
+		})
+	})
+
+	Describe("Checkpoint actions", func() {
+		BeforeEach(func() {
+			err := clientProcessor.Client(3).Propose(0, data)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("removes clients dropped from the checkpoint on the next invocation", func() {
+			clientStates := []*msgs.NetworkState_Client{{Id: 3, Width: 100}}
+			_, err := clientProcessor.Process((&statemachine.ActionList{}).Checkpoint(10, &msgs.NetworkState_Config{}, clientStates))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = clientProcessor.Process((&statemachine.ActionList{}).Checkpoint(20, &msgs.NetworkState_Config{}, nil))
+			Expect(err).NotTo(HaveOccurred())
+
+			By("retaining the requests until the next invocation")
+			storedData, err := reqStore.GetRequest(ack)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storedData).To(Equal(data))
+
+			_, err = clientProcessor.Process(&statemachine.ActionList{})
+			Expect(err).NotTo(HaveOccurred())
+
+			storedData, err = reqStore.GetRequest(ack)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storedData).To(BeNil())
+
+			digest, err := reqStore.GetAllocation(3, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(BeNil())
+
+			_, err = clientProcessor.Client(3).NextReqNo()
+			Expect(err).To(Equal(mirbft.ErrClientNotExist))
+		})
+
+		It("retains clients which were never checkpointed", func() {
+			clientStates := []*msgs.NetworkState_Client{{Id: 4, Width: 100}}
+			_, err := clientProcessor.Process((&statemachine.ActionList{}).Checkpoint(10, &msgs.NetworkState_Config{}, clientStates))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = clientProcessor.Process((&statemachine.ActionList{}).Checkpoint(20, &msgs.NetworkState_Config{}, clientStates))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = clientProcessor.Process(&statemachine.ActionList{})
+			Expect(err).NotTo(HaveOccurred())
+
+			storedData, err := reqStore.GetRequest(ack)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storedData).To(Equal(data))
+
+			nextReqNo, err := clientProcessor.Client(3).NextReqNo()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nextReqNo).To(Equal(uint64(1)))
+		})
+
+		It("retains clients present in the checkpoint", func() {
+			clientStates := []*msgs.NetworkState_Client{{Id: 3, Width: 100}}
+			_, err := clientProcessor.Process((&statemachine.ActionList{}).Checkpoint(10, &msgs.NetworkState_Config{}, clientStates))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = clientProcessor.Process(&statemachine.ActionList{})
+			Expect(err).NotTo(HaveOccurred())
+
+			storedData, err := reqStore.GetRequest(ack)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storedData).To(Equal(data))
+		})
+
+		It("garbage collects below the previous checkpoint's low watermarks", func() {
+			clientStates := []*msgs.NetworkState_Client{{Id: 3, Width: 100, LowWatermark: 1}}
+			_, err := clientProcessor.Process((&statemachine.ActionList{}).Checkpoint(10, &msgs.NetworkState_Config{}, clientStates))
+			Expect(err).NotTo(HaveOccurred())
+
+			By("retaining the requests until the next checkpoint")
+			storedData, err := reqStore.GetRequest(ack)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storedData).To(Equal(data))
+
+			_, err = clientProcessor.Process((&statemachine.ActionList{}).Checkpoint(20, &msgs.NetworkState_Config{}, clientStates))
+			Expect(err).NotTo(HaveOccurred())
+
+			storedData, err = reqStore.GetRequest(ack)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storedData).To(BeNil())
+
+			digest, err := reqStore.GetAllocation(3, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(BeNil())
+		})
+	})
+
+	Describe("ProposeNull", func() {
+		nullAck := &msgs.RequestAck{
+			ClientId: 3,
+			ReqNo:    0,
+		}
+
+		It("makes the null request available to the state machine", func() {
+			Expect(clientProcessor.Client(3).ProposeNull(0)).To(Succeed())
+
+			Eventually(clientProcessor.ClientWork.Ready()).Should(BeClosed())
+			results := clientProcessor.ClientWork.Results()
+			Expect(results.Len()).To(Equal(1))
+			persisted := results.Iterator().Next().Type.(*state.Event_RequestPersisted).RequestPersisted
+			Expect(persisted.RequestAck).To(Equal(nullAck))
+
+			nextReqNo, err := clientProcessor.Client(3).NextReqNo()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nextReqNo).To(Equal(uint64(1)))
+
+			By("ignoring a second null request for the same request number")
+			Expect(clientProcessor.Client(3).ProposeNull(0)).To(Succeed())
+			Consistently(clientProcessor.ClientWork.Ready(), 20*time.Millisecond).ShouldNot(BeClosed())
+		})
+
+		It("rejects later proposals for the request number", func() {
+			Expect(clientProcessor.Client(3).ProposeNull(1)).To(Succeed())
+			Expect(clientProcessor.Client(3).Propose(0, data)).To(Succeed())
+			err := clientProcessor.Client(3).Propose(1, data)
+			Expect(err).To(MatchError("a null request was proposed for req_no 1"))
+		})
+
+		It("rejects null requests for unknown clients", func() {
+			err := clientProcessor.Client(9).ProposeNull(0)
+			Expect(err).To(Equal(mirbft.ErrClientNotExist))
+		})
+	})
+})
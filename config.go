@@ -8,6 +8,7 @@ package mirbft
 
 import (
 	"github.com/IBM/mirbft/pkg/pb/state"
+	"github.com/IBM/mirbft/pkg/statemachine"
 )
 
 type Config struct {
@@ -21,6 +22,25 @@ type Config struct {
 	// before it is cut. (Note, batches may be cut earlier, so this is a max size).
 	BatchSize uint32
 
+	// MaxBatchBytes, if non-zero, determines how large a batch may grow (in
+	// total bytes of request data) before it is cut.  A single request larger
+	// than MaxBatchBytes is cut as a batch by itself.  Request sizes are
+	// reported by the acks of other replicas, and are not verified, so this
+	// is a bound on well-behaved batches only.
+	MaxBatchBytes uint32
+
+	// BatchTimeoutTicks, if non-zero, is the number of ticks a leader will
+	// wait for a batch to fill before cutting it anyway.  A small value
+	// favors latency under low load, while BatchSize and MaxBatchBytes
+	// determine batching under high load.  If zero, partial batches are
+	// only cut by heartbeats.
+	BatchTimeoutTicks uint32
+
+	// DigestLength is the size in bytes of the digests produced by the Hasher
+	// supplied to the processor.  If set, messages containing digests of any
+	// other length are rejected by ValidateMsg.
+	DigestLength int
+
 	// HeartbeatTicks is the number of ticks before a heartbeat is emitted
 	// by a leader.
 	HeartbeatTicks uint32
@@ -41,6 +61,13 @@ type Config struct {
 	// to a minimum of a few MB.
 	BufferSize uint32
 
+	// LeaderPolicy, if set, selects the leaders this node proposes when it
+	// is the primary for a new epoch.  If nil, the
+	// statemachine.DefaultLeaderPolicy is used.  As the state machine must
+	// be deterministic, the policy must be the same when replaying an event
+	// log.
+	LeaderPolicy statemachine.LeaderPolicy
+
 	// EventInterceptor, if set, has its Intercept method invoked each time the
 	// state machine undergoes some mutation.  This allows for additional
 	// external insight into the state machine, but comes at a performance cost
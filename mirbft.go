@@ -38,9 +38,18 @@ type WALStorage interface {
 // proposes new messages, receives delegated actions, and returns action results.
 // The methods exposed on Node are all thread safe, though typically, a single loop handles
 // reading Actions, writing results, and writing ticks, while other go routines Propose and Step.
+// Run provides such a loop.
 type Node struct {
 	Config *Config
-	s      *serializer
+
+	// ClientProcessor, if set, persists the requests submitted via Propose.
+	// It must be the ClientProcessor which handles this node's actions.
+	ClientProcessor *ClientProcessor
+
+	s *serializer
+
+	reconfigurations reconfigurations
+	proposals        proposals
 }
 
 func StandardInitialNetworkState(nodeCount int, clientCount int) *msgs.NetworkState {
@@ -134,10 +143,14 @@ func RestartNode(
 		return nil, errors.Errorf("failed to start new node: %s", err)
 	}
 
-	return &Node{
+	node := &Node{
 		Config: config,
 		s:      serializer,
-	}, nil
+	}
+
+	go node.proposals.failOnExit(serializer.errC, serializer.getExitErr)
+
+	return node, nil
 }
 
 // Stop terminates the resources associated with the node
@@ -175,6 +188,34 @@ func (n *Node) Status(ctx context.Context) (*status.StateMachine, error) {
 	return n.s.exitStatus, n.s.exitErr
 }
 
+// ClientStatus returns this node's view of the network's progress for the
+// given client.  The low watermark is the client's committed low watermark as
+// of the last stable checkpoint, the high watermark is the highest request
+// number allocated for the client, and the allocated entries indicate for
+// which request numbers in between some replica has acked a request, or a
+// request has committed.  It is intended for clients recovering from a crash,
+// which must gather the status from at least f+1 replicas, as any one replica
+// may be faulty or behind, see Client.Recover.  If the client does not exist,
+// ErrClientNotExist is returned.  Errors are otherwise as for Status.
+func (n *Node) ClientStatus(ctx context.Context, clientID uint64) (*status.ClientTracker, error) {
+	s, err := n.Status(ctx)
+	if s == nil {
+		return nil, err
+	}
+
+	for _, clientStatus := range s.ClientWindows {
+		if clientStatus.ClientID == clientID {
+			return clientStatus, err
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, ErrClientNotExist
+}
+
 // Ready returns a channel which will deliver Actions for the user to perform.
 // See the documentation for Actions regarding the detailed responsibilities
 // of the caller.
@@ -193,6 +234,24 @@ func (n *Node) Err() <-chan struct{} {
 	return n.s.errC
 }
 
+// Step validates a message received from another node with ValidateMsg,
+// and if it is well formed, injects it into the state machine.  If the message
+// is malformed, a *ValidationError is returned and the message is discarded.
+// Forwarded requests are not stepped into the state machine, and must instead
+// be passed to ClientProcessor.StepForwardRequest.
+// If the node is stopped, it returns the exit error.
+func (n *Node) Step(source uint64, msg *msgs.Msg) error {
+	if err := ValidateMsg(msg, n.Config); err != nil {
+		return err
+	}
+
+	if _, ok := msg.Type.(*msgs.Msg_ForwardRequest); ok {
+		return errors.Errorf("forwarded requests must be stepped into the ClientProcessor")
+	}
+
+	return n.InjectEvents((&statemachine.EventList{}).Step(source, msg))
+}
+
 // InjectEvents is called by the consumer after processing actions, or because
 // events such as network sends or client requests have occurred.
 // If the node is stopped, it returns the exit error otherwise nil is returned.
@@ -21,14 +21,15 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/IBM/mirbft"
 	"github.com/IBM/mirbft/pkg/eventlog"
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 	"github.com/IBM/mirbft/pkg/reqstore"
 	"github.com/IBM/mirbft/pkg/simplewal"
-	"github.com/IBM/mirbft/pkg/statemachine"
 	"github.com/IBM/mirbft/pkg/status"
+	"github.com/IBM/mirbft/pkg/transport/loopback"
 )
 
 var (
@@ -64,105 +65,21 @@ type FakeClient struct {
 	MsgCount uint64
 }
 
-type SourceMsg struct {
-	Source uint64
-	Msg    *msgs.Msg
+// FakeBroadcastLink implements mirbft.BroadcastLink by decoding each
+// broadcast message once for the loopback transport.
+type FakeBroadcastLink struct {
+	*loopback.Link
 }
 
-type FakeLink struct {
-	FakeTransport *FakeTransport
-	Source        uint64
-}
-
-func (fl *FakeLink) Send(dest uint64, msg *msgs.Msg) {
-	fl.FakeTransport.Send(fl.Source, dest, msg)
-}
-
-type FakeTransport struct {
-	// Buffers is source x dest
-	Buffers   [][]chan *msgs.Msg
-	NodeSinks []chan SourceMsg
-	WaitGroup sync.WaitGroup
-	DoneC     chan struct{}
-}
-
-func NewFakeTransport(nodes int) *FakeTransport {
-	buffers := make([][]chan *msgs.Msg, nodes)
-	nodeSinks := make([]chan SourceMsg, nodes)
-	for i := 0; i < nodes; i++ {
-		buffers[i] = make([]chan *msgs.Msg, nodes)
-		for j := 0; j < nodes; j++ {
-			if i == j {
-				continue
-			}
-			buffers[i][j] = make(chan *msgs.Msg, 10000)
-		}
-		nodeSinks[i] = make(chan SourceMsg)
-	}
-
-	return &FakeTransport{
-		Buffers:   buffers,
-		NodeSinks: nodeSinks,
-		DoneC:     make(chan struct{}),
-	}
-}
-
-func (ft *FakeTransport) Send(source, dest uint64, msg *msgs.Msg) {
-	select {
-	case ft.Buffers[int(source)][int(dest)] <- msg:
-	default:
-		fmt.Printf("Warning: Dropping message %T from %d to %d\n", msg.Type, source, dest)
-	}
-}
-
-func (ft *FakeTransport) Link(source uint64) *FakeLink {
-	return &FakeLink{
-		Source:        source,
-		FakeTransport: ft,
-	}
-}
-
-func (ft *FakeTransport) RecvC(dest uint64) <-chan SourceMsg {
-	return ft.NodeSinks[int(dest)]
-}
-
-func (ft *FakeTransport) Start() {
-	for i, sourceBuffers := range ft.Buffers {
-		for j, buffer := range sourceBuffers {
-			if i == j {
-				continue
-			}
-
-			ft.WaitGroup.Add(1)
-			go func(i, j int, buffer chan *msgs.Msg) {
-				// fmt.Printf("Starting drain thread from %d to %d\n", i, j)
-				defer ft.WaitGroup.Done()
-				for {
-					select {
-					case msg := <-buffer:
-						// fmt.Printf("Sending message from %d to %d\n", i, j)
-						select {
-						case ft.NodeSinks[j] <- SourceMsg{
-							Source: uint64(i),
-							Msg:    msg,
-						}:
-						case <-ft.DoneC:
-							return
-						}
-					case <-ft.DoneC:
-						return
-					}
-				}
-			}(i, j, buffer)
-		}
+func (fbl *FakeBroadcastLink) Broadcast(dests []uint64, msgBytes []byte) {
+	msg := &msgs.Msg{}
+	err := proto.Unmarshal(msgBytes, msg)
+	Expect(err).NotTo(HaveOccurred())
+	for _, dest := range dests {
+		fbl.Link.Send(dest, msg)
 	}
 }
 
-func (ft *FakeTransport) Stop() {
-	close(ft.DoneC)
-	ft.WaitGroup.Wait()
-}
-
 type FakeApp struct {
 	Entries []*msgs.QEntry
 	CommitC chan *msgs.QEntry
@@ -195,6 +112,7 @@ type TestConfig struct {
 	BatchSize          uint32
 	ClientWidth        uint32
 	ParallelProcess    bool
+	Broadcast          bool
 }
 
 func Uint64ToBytes(value uint64) []byte {
@@ -309,6 +227,13 @@ var _ = Describe("StressyTest", func() {
 			MsgCount:           1000,
 		}),
 
+		Entry("FourNodeBFT broadcast greenpath", &TestConfig{
+			NodeCount:          4,
+			CheckpointInterval: 20,
+			MsgCount:           1000,
+			Broadcast:          true,
+		}),
+
 		Entry("FourNodeBFT single bucket greenpath", &TestConfig{
 			NodeCount:          4,
 			BucketCount:        1,
@@ -323,7 +248,15 @@ var _ = Describe("StressyTest", func() {
 			BatchSize:          10,
 			ClientWidth:        1000,
 			MsgCount:           10000,
-			// ParallelProcess:    true, // TODO, re-enable once parallel processing exists again
+			ParallelProcess:    true,
+		}),
+
+		Entry("FourNodeBFT ParallelProcessor+Broadcast greenpath", &TestConfig{
+			NodeCount:          4,
+			CheckpointInterval: 20,
+			MsgCount:           1000,
+			ParallelProcess:    true,
+			Broadcast:          true,
 		}),
 	)
 })
@@ -333,9 +266,10 @@ type TestReplica struct {
 	InitialNetworkState *msgs.NetworkState
 	TmpDir              string
 	App                 *FakeApp
-	FakeTransport       *FakeTransport
+	Transport           *loopback.Transport
 	FakeClient          *FakeClient
 	ParallelProcess     bool
+	Broadcast           bool
 	DoneC               <-chan struct{}
 }
 
@@ -351,11 +285,6 @@ func clientReq(clientID, reqNo uint64) []byte {
 }
 
 func (tr *TestReplica) Run() (*status.StateMachine, error) {
-	eventsC := make(chan *statemachine.EventList)
-
-	ticker := time.NewTicker(tickInterval)
-	defer ticker.Stop()
-
 	reqStorePath := filepath.Join(tr.TmpDir, "reqstore")
 	err := os.MkdirAll(reqStorePath, 0700)
 	Expect(err).NotTo(HaveOccurred())
@@ -390,34 +319,40 @@ func (tr *TestReplica) Run() (*status.StateMachine, error) {
 	Expect(err).NotTo(HaveOccurred())
 	defer node.Stop()
 
+	clientProcessor := &mirbft.ClientProcessor{
+		NodeID:       node.Config.ID,
+		RequestStore: reqStore,
+		Hasher:       crypto.SHA256,
+		Link:         tr.Transport.Link(node.Config.ID),
+	}
+
 	wg.Add(1)
 	go func() {
+		defer GinkgoRecover()
 		defer wg.Done()
-		recvC := tr.FakeTransport.RecvC(node.Config.ID)
-		events := &statemachine.EventList{}
-		var eC chan *statemachine.EventList
+		recvC := tr.Transport.RecvC(node.Config.ID)
 		for {
 			select {
 			case sourceMsg := <-recvC:
-				events.Step(sourceMsg.Source, sourceMsg.Msg)
-				if eC == nil {
-					eC = eventsC
+				if fr, ok := sourceMsg.Msg.Type.(*msgs.Msg_ForwardRequest); ok {
+					// Forwarded requests go to the client processor, not the state machine
+					clientProcessor.StepForwardRequest(sourceMsg.Source, fr.ForwardRequest)
+					continue
 				}
-			case eC <- events:
-				events = &statemachine.EventList{}
-				eC = nil
+				err := node.Step(sourceMsg.Source, sourceMsg.Msg)
+				select {
+				case <-node.Err():
+					return
+				default:
+				}
+				// Correct replicas should never send malformed messages
+				Expect(err).NotTo(HaveOccurred())
 			case <-node.Err():
 				return
 			}
 		}
 	}()
 
-	clientProcessor := &mirbft.ClientProcessor{
-		NodeID:       node.Config.ID,
-		RequestStore: reqStore,
-		Hasher:       crypto.SHA256,
-	}
-
 	expectedProposalCount := tr.FakeClient.MsgCount
 	Expect(expectedProposalCount).NotTo(Equal(0))
 
@@ -458,77 +393,52 @@ func (tr *TestReplica) Run() (*status.StateMachine, error) {
 		}
 	}()
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		defer GinkgoRecover()
-		processor := &mirbft.Processor{
+	var link mirbft.Link = tr.Transport.Link(node.Config.ID)
+	if tr.Broadcast {
+		link = &FakeBroadcastLink{Link: tr.Transport.Link(node.Config.ID)}
+	}
+
+	var processor mirbft.ActionProcessor
+	if tr.ParallelProcess {
+		processor = &mirbft.ParallelProcessor{
+			NodeID:          node.Config.ID,
+			Link:            link,
+			Hasher:          crypto.SHA256,
+			App:             tr.App,
+			WAL:             wal,
+			ClientProcessor: clientProcessor,
+		}
+	} else {
+		processor = &mirbft.Processor{
 			NodeID: node.Config.ID,
-			Link:   tr.FakeTransport.Link(node.Config.ID),
+			Link:   link,
 			Hasher: crypto.SHA256,
 			App:    tr.App,
 			WAL:    wal,
 		}
+	}
 
-		events := &statemachine.EventList{}
-		var eC chan *statemachine.EventList
-		for {
-			var err error
-			select {
-			case actions := <-node.Actions():
-				var newEvents *statemachine.EventList
-				newEvents, err = processor.Process(actions)
-				if err != nil {
-					break
-				}
-				events.PushBackList(newEvents)
-
-				newEvents, err = clientProcessor.Process(actions)
-				if err != nil {
-					break
-				}
-				events.PushBackList(newEvents)
-			case <-clientProcessor.ClientWork.Ready():
-				events.PushBackList(clientProcessor.ClientWork.Results())
-			case eC <- events:
-				events = &statemachine.EventList{}
-				eC = nil
-			case <-ticker.C:
-				events.TickElapsed()
-			case <-node.Err():
-				return
-			}
-
-			if err == mirbft.ErrStopped {
-				return
-			}
-			Expect(err).NotTo(HaveOccurred())
-
-			if events.Len() > 0 {
-				eC = eventsC
-			}
-		}
-	}()
-
-	for {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
 		select {
-		case events := <-eventsC:
-			err := node.InjectEvents(events)
-			if err == mirbft.ErrStopped {
-				return node.Status(context.Background())
-			}
-			Expect(err).NotTo(HaveOccurred())
-		case <-node.Err():
-			return node.Status(context.Background())
 		case <-tr.DoneC:
-			node.Stop()
-			return node.Status(context.Background())
+			cancel()
+		case <-ctx.Done():
 		}
-	}
+	}()
+
+	node.Run(ctx, mirbft.RunConfig{
+		Processor:       processor,
+		ClientProcessor: clientProcessor,
+		TickInterval:    tickInterval,
+	})
+
+	return node.Status(context.Background())
 }
 
 type Network struct {
-	Transport    *FakeTransport
+	Transport    *loopback.Transport
 	TestReplicas []*TestReplica
 }
 
@@ -538,7 +448,7 @@ type NodeStatus struct {
 }
 
 func CreateNetwork(testConfig *TestConfig, doneC <-chan struct{}) *Network {
-	transport := NewFakeTransport(testConfig.NodeCount)
+	transport := loopback.New(testConfig.NodeCount, 0)
 
 	networkState := mirbft.StandardInitialNetworkState(testConfig.NodeCount, 1)
 
@@ -565,6 +475,7 @@ func CreateNetwork(testConfig *TestConfig, doneC <-chan struct{}) *Network {
 		config := &mirbft.Config{
 			ID:                   uint64(i),
 			BatchSize:            1,
+			DigestLength:         crypto.SHA256.Size(),
 			SuspectTicks:         4,
 			HeartbeatTicks:       2,
 			NewEpochTimeoutTicks: 8,
@@ -587,11 +498,12 @@ func CreateNetwork(testConfig *TestConfig, doneC <-chan struct{}) *Network {
 			InitialNetworkState: networkState,
 			TmpDir:              filepath.Join(tmpDir, fmt.Sprintf("node%d", i)),
 			App:                 fakeApp,
-			FakeTransport:       transport,
+			Transport:           transport,
 			FakeClient: &FakeClient{
 				MsgCount: uint64(testConfig.MsgCount),
 			},
 			ParallelProcess: testConfig.ParallelProcess,
+			Broadcast:       testConfig.Broadcast,
 			DoneC:           doneC,
 		}
 	}
@@ -7,100 +7,322 @@ SPDX-License-Identifier: Apache-2.0
 package mirbft
 
 import (
+	"fmt"
+
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 
 	"github.com/pkg/errors"
 )
 
-// TODO, we probably can/should add some more basic error checking here.  Particularly
-// identifying pointer fields which must be set.
+// The reasons a message may fail validation, as reported by ValidationError.
+var (
+	ErrUnknownType  = errors.New("unknown message type")
+	ErrMissingField = errors.New("required field is not set")
+	ErrDigestLength = errors.New("digest has the wrong length")
+	ErrBatchSize    = errors.New("batch exceeds the maximum batch size")
+	ErrOutOfRange   = errors.New("value is out of range")
+)
+
+// ValidationError is returned by ValidateMsg when a message is malformed.
+type ValidationError struct {
+	// Field is the path of the offending field within the message,
+	// for instance 'preprepare.batch[2].digest'.
+	Field string
+
+	// Reason is one of the Err* validation reasons.
+	Reason error
 
-var _ = preProcess // XXX temporary hack to make staticccheck not made
+	// Detail optionally further describes the failure.
+	Detail string
+}
+
+func (ve *ValidationError) Error() string {
+	if ve.Detail == "" {
+		return fmt.Sprintf("invalid field '%s': %s", ve.Field, ve.Reason)
+	}
+	return fmt.Sprintf("invalid field '%s': %s: %s", ve.Field, ve.Reason, ve.Detail)
+}
 
-func preProcess(outerMsg *msgs.Msg) error {
-	switch innerMsg := outerMsg.Type.(type) {
+// ValidateMsg checks that a message received from the network is well formed,
+// so that it may be safely stepped into the state machine.  Every field the
+// state machine requires must be set, digests must be config.DigestLength bytes
+// (if configured), batches may contain at most config.BatchSize requests (if
+// configured), and sequence and epoch numbers must be plausible.  Note, this
+// validation is stateless, it does not check that the message is valid with
+// respect to the current state of the network, which the state machine does.
+func ValidateMsg(msg *msgs.Msg, config *Config) error {
+	v := &validator{
+		digestLength: config.DigestLength,
+		batchSize:    config.BatchSize,
+	}
+
+	switch innerMsg := msg.Type.(type) {
 	case *msgs.Msg_Preprepare:
-		if innerMsg.Preprepare == nil {
-			return errors.Errorf("message of type Preprepare, but preprepare field is nil")
-		}
+		return v.preprepare("preprepare", innerMsg.Preprepare)
 	case *msgs.Msg_Prepare:
 		if innerMsg.Prepare == nil {
-			return errors.Errorf("message of type Prepare, but prepare field is nil")
+			return missing("prepare")
 		}
+		return v.seqNoBatchDigest("prepare", innerMsg.Prepare.SeqNo, innerMsg.Prepare.Digest)
 	case *msgs.Msg_Commit:
 		if innerMsg.Commit == nil {
-			return errors.Errorf("message of type Commit, but commit field is nil")
+			return missing("commit")
 		}
+		return v.seqNoBatchDigest("commit", innerMsg.Commit.SeqNo, innerMsg.Commit.Digest)
 	case *msgs.Msg_Suspect:
 		if innerMsg.Suspect == nil {
-			return errors.Errorf("message of type Suspect, but suspect field is nil")
+			return missing("suspect")
 		}
 	case *msgs.Msg_Checkpoint:
 		if innerMsg.Checkpoint == nil {
-			return errors.Errorf("message of type Checkpoint, but checkpoint field is nil")
+			return missing("checkpoint")
 		}
 	case *msgs.Msg_RequestAck:
-		if innerMsg.RequestAck == nil {
-			return errors.Errorf("message of type RequestAck, but request_ack field is nil")
-		}
+		return v.requestAck("request_ack", innerMsg.RequestAck)
 	case *msgs.Msg_FetchRequest:
-		if innerMsg.FetchRequest == nil {
-			return errors.Errorf("message of type FetchRequest, but fetch_request field is nil")
-		}
+		return v.requestAck("fetch_request", innerMsg.FetchRequest)
 	case *msgs.Msg_ForwardRequest:
 		if innerMsg.ForwardRequest == nil {
-			return errors.Errorf("message of type ForwardRequest, but forward_request field is nil")
-		}
-		if innerMsg.ForwardRequest.RequestAck == nil {
-			return errors.Errorf("message of type ForwardRequest, but forward_request's request_ack field is nil")
+			return missing("forward_request")
 		}
+		return v.requestAck("forward_request.request_ack", innerMsg.ForwardRequest.RequestAck)
 	case *msgs.Msg_FetchBatch:
 		if innerMsg.FetchBatch == nil {
-			return errors.Errorf("message of type FetchBatch, but fetch_batch field is nil")
+			return missing("fetch_batch")
 		}
+		return v.seqNoDigest("fetch_batch", innerMsg.FetchBatch.SeqNo, innerMsg.FetchBatch.Digest)
 	case *msgs.Msg_ForwardBatch:
-		if innerMsg.ForwardBatch == nil {
-			return errors.Errorf("message of type ForwardBatch, but forward_batch field is nil")
-		}
+		return v.forwardBatch("forward_batch", innerMsg.ForwardBatch)
 	case *msgs.Msg_EpochChange:
-		if innerMsg.EpochChange == nil {
-			return errors.Errorf("message of type EpochChange, but epoch_change field is nil")
-		}
+		return v.epochChange("epoch_change", innerMsg.EpochChange)
 	case *msgs.Msg_EpochChangeAck:
 		if innerMsg.EpochChangeAck == nil {
-			return errors.Errorf("message of type EpochChangeAck, but epoch_change_ack field is nil")
+			return missing("epoch_change_ack")
 		}
+		return v.epochChange("epoch_change_ack.epoch_change", innerMsg.EpochChangeAck.EpochChange)
 	case *msgs.Msg_NewEpoch:
-		switch {
-		case innerMsg.NewEpoch == nil:
-			return errors.Errorf("message of type NewEpoch, but new_epoch field is nil")
-		case innerMsg.NewEpoch.NewConfig == nil:
-			return errors.Errorf("NewEpoch has nil NewConfig")
-		case innerMsg.NewEpoch.NewConfig.Config == nil:
-			return errors.Errorf("NewEpoch has nil NewConfig.Config")
-		case innerMsg.NewEpoch.NewConfig.StartingCheckpoint == nil:
-			return errors.Errorf("NewEpoch Config has nil StartingCheckpoint")
-		}
+		return v.newEpoch("new_epoch", innerMsg.NewEpoch)
 	case *msgs.Msg_NewEpochEcho:
-		switch {
-		case innerMsg.NewEpochEcho == nil:
-			return errors.Errorf("message of type NewEpochEcho, but new_epoch_echo field is nil")
-		case innerMsg.NewEpochEcho.Config == nil:
-			return errors.Errorf("NewEpochEcho has nil Config")
-		case innerMsg.NewEpochEcho.StartingCheckpoint == nil:
-			return errors.Errorf("NewEpochEcho has nil StartingCheckpoint")
-		}
+		return v.newEpochConfig("new_epoch_echo", innerMsg.NewEpochEcho)
 	case *msgs.Msg_NewEpochReady:
-		switch {
-		case innerMsg.NewEpochReady == nil:
-			return errors.Errorf("message of type NewEpochReady, but new_epoch_ready field is nil")
-		case innerMsg.NewEpochReady.Config == nil:
-			return errors.Errorf("NewEpochReady has nil Config")
-		case innerMsg.NewEpochReady.StartingCheckpoint == nil:
-			return errors.Errorf("NewEpochReady has nil StartingCheckpoint")
-		}
+		return v.newEpochConfig("new_epoch_ready", innerMsg.NewEpochReady)
 	default:
-		return errors.Errorf("unknown type '%T' for message", outerMsg.Type)
+		return &ValidationError{
+			Field:  "type",
+			Reason: ErrUnknownType,
+			Detail: fmt.Sprintf("%T", msg.Type),
+		}
+	}
+
+	return nil
+}
+
+func missing(field string) error {
+	return &ValidationError{
+		Field:  field,
+		Reason: ErrMissingField,
+	}
+}
+
+func outOfRange(field string, format string, args ...interface{}) error {
+	return &ValidationError{
+		Field:  field,
+		Reason: ErrOutOfRange,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
+
+type validator struct {
+	digestLength int
+	batchSize    uint32
+}
+
+func (v *validator) digest(field string, digest []byte) error {
+	if v.digestLength == 0 || len(digest) == v.digestLength {
+		return nil
+	}
+
+	return &ValidationError{
+		Field:  field,
+		Reason: ErrDigestLength,
+		Detail: fmt.Sprintf("expected %d bytes, got %d", v.digestLength, len(digest)),
+	}
+}
+
+// batchDigest is like digest, but permits the empty digest, which
+// denotes a null batch, as may be committed during an epoch change.
+func (v *validator) batchDigest(field string, digest []byte) error {
+	if len(digest) == 0 {
+		return nil
+	}
+	return v.digest(field, digest)
+}
+
+func (v *validator) seqNo(field string, seqNo uint64) error {
+	// Sequence numbers begin at 1, 0 is reserved for the genesis checkpoint.
+	if seqNo == 0 {
+		return outOfRange(field, "sequence number must be greater than 0")
+	}
+	return nil
+}
+
+func (v *validator) seqNoDigest(field string, seqNo uint64, digest []byte) error {
+	if err := v.seqNo(field+".seq_no", seqNo); err != nil {
+		return err
+	}
+	return v.digest(field+".digest", digest)
+}
+
+func (v *validator) seqNoBatchDigest(field string, seqNo uint64, digest []byte) error {
+	if err := v.seqNo(field+".seq_no", seqNo); err != nil {
+		return err
+	}
+	return v.batchDigest(field+".digest", digest)
+}
+
+// requestAck permits the empty digest, which denotes a null request.
+func (v *validator) requestAck(field string, ack *msgs.RequestAck) error {
+	if ack == nil {
+		return missing(field)
+	}
+	return v.batchDigest(field+".digest", ack.Digest)
+}
+
+func (v *validator) batch(field string, acks []*msgs.RequestAck) error {
+	if v.batchSize != 0 && len(acks) > int(v.batchSize) {
+		return &ValidationError{
+			Field:  field,
+			Reason: ErrBatchSize,
+			Detail: fmt.Sprintf("batch contains %d requests, but the maximum is %d", len(acks), v.batchSize),
+		}
+	}
+
+	for i, ack := range acks {
+		if err := v.requestAck(fmt.Sprintf("%s[%d]", field, i), ack); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) preprepare(field string, preprepare *msgs.Preprepare) error {
+	if preprepare == nil {
+		return missing(field)
+	}
+
+	if err := v.seqNo(field+".seq_no", preprepare.SeqNo); err != nil {
+		return err
+	}
+
+	return v.batch(field+".batch", preprepare.Batch)
+}
+
+func (v *validator) forwardBatch(field string, forwardBatch *msgs.ForwardBatch) error {
+	if forwardBatch == nil {
+		return missing(field)
+	}
+
+	if err := v.seqNoDigest(field, forwardBatch.SeqNo, forwardBatch.Digest); err != nil {
+		return err
+	}
+
+	return v.batch(field+".request_acks", forwardBatch.RequestAcks)
+}
+
+func (v *validator) epochChange(field string, epochChange *msgs.EpochChange) error {
+	if epochChange == nil {
+		return missing(field)
+	}
+
+	// Epoch 0 is the genesis epoch, and is never the target of an epoch change.
+	if epochChange.NewEpoch == 0 {
+		return outOfRange(field+".new_epoch", "new epoch must be greater than 0")
+	}
+
+	if len(epochChange.Checkpoints) == 0 {
+		return missing(field + ".checkpoints")
+	}
+
+	for i, checkpoint := range epochChange.Checkpoints {
+		if checkpoint == nil {
+			return missing(fmt.Sprintf("%s.checkpoints[%d]", field, i))
+		}
+	}
+
+	sets := []struct {
+		name    string
+		entries []*msgs.EpochChange_SetEntry
+	}{
+		{"p_set", epochChange.PSet},
+		{"q_set", epochChange.QSet},
+	}
+
+	for _, set := range sets {
+		for i, entry := range set.entries {
+			entryField := fmt.Sprintf("%s.%s[%d]", field, set.name, i)
+			if entry == nil {
+				return missing(entryField)
+			}
+
+			if entry.Epoch >= epochChange.NewEpoch {
+				return outOfRange(entryField+".epoch", "epoch %d is not prior to new epoch %d", entry.Epoch, epochChange.NewEpoch)
+			}
+
+			if err := v.seqNoBatchDigest(entryField, entry.SeqNo, entry.Digest); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) newEpochConfig(field string, newEpochConfig *msgs.NewEpochConfig) error {
+	switch {
+	case newEpochConfig == nil:
+		return missing(field)
+	case newEpochConfig.Config == nil:
+		return missing(field + ".config")
+	case newEpochConfig.StartingCheckpoint == nil:
+		return missing(field + ".starting_checkpoint")
+	}
+
+	if newEpochConfig.Config.Number == 0 {
+		return outOfRange(field+".config.number", "new epoch must be greater than 0")
+	}
+
+	if len(newEpochConfig.Config.Leaders) == 0 {
+		return missing(field + ".config.leaders")
+	}
+
+	for i, digest := range newEpochConfig.FinalPreprepares {
+		if err := v.batchDigest(fmt.Sprintf("%s.final_preprepares[%d]", field, i), digest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) newEpoch(field string, newEpoch *msgs.NewEpoch) error {
+	if newEpoch == nil {
+		return missing(field)
+	}
+
+	if err := v.newEpochConfig(field+".new_config", newEpoch.NewConfig); err != nil {
+		return err
+	}
+
+	for i, remoteEpochChange := range newEpoch.EpochChanges {
+		remoteField := fmt.Sprintf("%s.epoch_changes[%d]", field, i)
+		if remoteEpochChange == nil {
+			return missing(remoteField)
+		}
+
+		if err := v.digest(remoteField+".digest", remoteEpochChange.Digest); err != nil {
+			return err
+		}
 	}
 
 	return nil
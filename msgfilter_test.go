@@ -0,0 +1,211 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirbft_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+)
+
+var _ = Describe("ValidateMsg", func() {
+	var (
+		config *mirbft.Config
+		digest []byte
+	)
+
+	BeforeEach(func() {
+		config = &mirbft.Config{
+			BatchSize:    2,
+			DigestLength: 4,
+		}
+		digest = []byte("abcd")
+	})
+
+	It("accepts well formed messages", func() {
+		Expect(mirbft.ValidateMsg(&msgs.Msg{
+			Type: &msgs.Msg_Preprepare{
+				Preprepare: &msgs.Preprepare{
+					SeqNo: 1,
+					Batch: []*msgs.RequestAck{
+						{ClientId: 1, ReqNo: 1, Digest: digest},
+						{ClientId: 1, ReqNo: 2, Digest: digest},
+					},
+				},
+			},
+		}, config)).To(Succeed())
+
+		Expect(mirbft.ValidateMsg(&msgs.Msg{
+			Type: &msgs.Msg_NewEpochEcho{
+				NewEpochEcho: &msgs.NewEpochConfig{
+					Config: &msgs.EpochConfig{
+						Number:  1,
+						Leaders: []uint64{0},
+					},
+					StartingCheckpoint: &msgs.Checkpoint{},
+					FinalPreprepares:   [][]byte{digest, nil},
+				},
+			},
+		}, config)).To(Succeed())
+
+		By("permitting the empty digest of a null request")
+		Expect(mirbft.ValidateMsg(&msgs.Msg{
+			Type: &msgs.Msg_RequestAck{
+				RequestAck: &msgs.RequestAck{
+					ClientId: 1,
+					ReqNo:    1,
+				},
+			},
+		}, config)).To(Succeed())
+
+		By("permitting null requests within batches")
+		Expect(mirbft.ValidateMsg(&msgs.Msg{
+			Type: &msgs.Msg_Preprepare{
+				Preprepare: &msgs.Preprepare{
+					SeqNo: 1,
+					Batch: []*msgs.RequestAck{
+						{ClientId: 1, ReqNo: 1},
+						{ClientId: 1, ReqNo: 2, Digest: digest},
+					},
+				},
+			},
+		}, config)).To(Succeed())
+
+		Expect(mirbft.ValidateMsg(&msgs.Msg{
+			Type: &msgs.Msg_ForwardBatch{
+				ForwardBatch: &msgs.ForwardBatch{
+					SeqNo:  1,
+					Digest: digest,
+					RequestAcks: []*msgs.RequestAck{
+						{ClientId: 1, ReqNo: 1},
+					},
+				},
+			},
+		}, config)).To(Succeed())
+
+		By("permitting the empty digest of a null batch")
+		Expect(mirbft.ValidateMsg(&msgs.Msg{
+			Type: &msgs.Msg_Prepare{
+				Prepare: &msgs.Prepare{
+					SeqNo: 1,
+				},
+			},
+		}, config)).To(Succeed())
+	})
+
+	It("skips the digest and batch size checks when not configured", func() {
+		Expect(mirbft.ValidateMsg(&msgs.Msg{
+			Type: &msgs.Msg_ForwardBatch{
+				ForwardBatch: &msgs.ForwardBatch{
+					SeqNo: 1,
+					RequestAcks: []*msgs.RequestAck{
+						{ClientId: 1, ReqNo: 1},
+						{ClientId: 1, ReqNo: 2},
+						{ClientId: 1, ReqNo: 3},
+					},
+				},
+			},
+		}, &mirbft.Config{})).To(Succeed())
+	})
+
+	DescribeTable("rejects malformed messages",
+		func(msg *msgs.Msg, field string, reason error) {
+			err := mirbft.ValidateMsg(msg, config)
+			Expect(err).To(HaveOccurred())
+			validationErr, ok := err.(*mirbft.ValidationError)
+			Expect(ok).To(BeTrue())
+			Expect(validationErr.Field).To(Equal(field))
+			Expect(validationErr.Reason).To(Equal(reason))
+		},
+		Entry("unset type",
+			&msgs.Msg{},
+			"type", mirbft.ErrUnknownType,
+		),
+		Entry("nil inner message",
+			&msgs.Msg{Type: &msgs.Msg_Commit{}},
+			"commit", mirbft.ErrMissingField,
+		),
+		Entry("zero sequence number",
+			&msgs.Msg{Type: &msgs.Msg_Prepare{Prepare: &msgs.Prepare{Digest: []byte("abcd")}}},
+			"prepare.seq_no", mirbft.ErrOutOfRange,
+		),
+		Entry("short digest",
+			&msgs.Msg{Type: &msgs.Msg_Commit{Commit: &msgs.Commit{SeqNo: 1, Digest: []byte("ab")}}},
+			"commit.digest", mirbft.ErrDigestLength,
+		),
+		Entry("empty digest for a batch fetch",
+			&msgs.Msg{Type: &msgs.Msg_FetchBatch{FetchBatch: &msgs.FetchBatch{SeqNo: 1}}},
+			"fetch_batch.digest", mirbft.ErrDigestLength,
+		),
+		Entry("oversized batch",
+			&msgs.Msg{Type: &msgs.Msg_Preprepare{Preprepare: &msgs.Preprepare{
+				SeqNo: 1,
+				Batch: []*msgs.RequestAck{{}, {}, {}},
+			}}},
+			"preprepare.batch", mirbft.ErrBatchSize,
+		),
+		Entry("nil request in batch",
+			&msgs.Msg{Type: &msgs.Msg_Preprepare{Preprepare: &msgs.Preprepare{
+				SeqNo: 1,
+				Batch: []*msgs.RequestAck{{Digest: []byte("abcd")}, nil},
+			}}},
+			"preprepare.batch[1]", mirbft.ErrMissingField,
+		),
+		Entry("short request digest in batch",
+			&msgs.Msg{Type: &msgs.Msg_Preprepare{Preprepare: &msgs.Preprepare{
+				SeqNo: 1,
+				Batch: []*msgs.RequestAck{{}, {Digest: []byte("ab")}},
+			}}},
+			"preprepare.batch[1].digest", mirbft.ErrDigestLength,
+		),
+		Entry("forwarded request without ack",
+			&msgs.Msg{Type: &msgs.Msg_ForwardRequest{ForwardRequest: &msgs.ForwardRequest{}}},
+			"forward_request.request_ack", mirbft.ErrMissingField,
+		),
+		Entry("epoch change to the genesis epoch",
+			&msgs.Msg{Type: &msgs.Msg_EpochChange{EpochChange: &msgs.EpochChange{
+				Checkpoints: []*msgs.Checkpoint{{}},
+			}}},
+			"epoch_change.new_epoch", mirbft.ErrOutOfRange,
+		),
+		Entry("epoch change without checkpoints",
+			&msgs.Msg{Type: &msgs.Msg_EpochChangeAck{EpochChangeAck: &msgs.EpochChangeAck{
+				EpochChange: &msgs.EpochChange{NewEpoch: 2},
+			}}},
+			"epoch_change_ack.epoch_change.checkpoints", mirbft.ErrMissingField,
+		),
+		Entry("epoch change with entry from a future epoch",
+			&msgs.Msg{Type: &msgs.Msg_EpochChange{EpochChange: &msgs.EpochChange{
+				NewEpoch:    2,
+				Checkpoints: []*msgs.Checkpoint{{}},
+				QSet: []*msgs.EpochChange_SetEntry{
+					{Epoch: 1, SeqNo: 1, Digest: []byte("abcd")},
+					{Epoch: 2, SeqNo: 2, Digest: []byte("abcd")},
+				},
+			}}},
+			"epoch_change.q_set[1].epoch", mirbft.ErrOutOfRange,
+		),
+		Entry("new epoch without a starting checkpoint",
+			&msgs.Msg{Type: &msgs.Msg_NewEpoch{NewEpoch: &msgs.NewEpoch{
+				NewConfig: &msgs.NewEpochConfig{
+					Config: &msgs.EpochConfig{Number: 1, Leaders: []uint64{0}},
+				},
+			}}},
+			"new_epoch.new_config.starting_checkpoint", mirbft.ErrMissingField,
+		),
+		Entry("new epoch ready without leaders",
+			&msgs.Msg{Type: &msgs.Msg_NewEpochReady{NewEpochReady: &msgs.NewEpochConfig{
+				Config:             &msgs.EpochConfig{Number: 1},
+				StartingCheckpoint: &msgs.Checkpoint{},
+			}}},
+			"new_epoch_ready.config.leaders", mirbft.ErrMissingField,
+		),
+	)
+})
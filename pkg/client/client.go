@@ -0,0 +1,286 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package client is a library for applications which submit requests to a
+// mirbft network.  It assigns request numbers, persisting the next request
+// number so that a request number is never reused with different data, sends
+// each request to every replica, and tracks when requests commit.
+//
+// Replicas do not send per request replies.  Instead, a request is known to
+// have committed once f+1 replicas (so at least one correct replica) report a
+// checkpointed client state in which the request is committed.  How these
+// client states are published to the client (for instance, by the application
+// after each checkpoint) is left to the application, which supplies them to
+// ReportClientState.
+package client
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/statemachine"
+)
+
+// Transport delivers requests from the client to the replicas.
+type Transport interface {
+	// Submit sends the request to the given replica.  An error indicates
+	// the request could not be sent, it should not block awaiting commit.
+	Submit(replica uint64, request *msgs.Request) error
+}
+
+// ReqNoStore durably stores the next request number of the client.
+type ReqNoStore interface {
+	// LoadNextReqNo returns the stored next request number, and false
+	// if no request number has ever been stored.
+	LoadNextReqNo() (uint64, bool, error)
+
+	// StoreNextReqNo durably stores the next request number, it must
+	// not return until the request number would survive a crash.
+	StoreNextReqNo(reqNo uint64) error
+}
+
+// Config is the configuration of a client.
+type Config struct {
+	// ClientState is the state of this client in the network state
+	// the client begins from, typically the initial network state.  Its
+	// Id and Width determine the client's ID and request window.
+	ClientState *msgs.NetworkState_Client
+
+	// Replicas are the IDs of the nodes in the network.
+	Replicas []uint64
+
+	// F is the number of byzantine faults the network tolerates.
+	F int
+
+	// Transport sends requests to the replicas.
+	Transport Transport
+
+	// ReqNoStore persists the client's next request number.
+	ReqNoStore ReqNoStore
+}
+
+// Client submits requests to the network, and tracks their commitment.  Its
+// methods are safe for concurrent use.
+type Client struct {
+	mutex  sync.Mutex
+	config *Config
+
+	// windowC is closed and replaced whenever the window advances.
+	windowC chan struct{}
+
+	nextReqNo      uint64
+	lowWatermark   uint64
+	replicaStates  map[uint64]*msgs.NetworkState_Client
+	pendingCommits map[uint64]*Future
+}
+
+// Future is resolved once its request is known to have committed.
+type Future struct {
+	// ReqNo is the request number assigned to the request.
+	ReqNo uint64
+
+	doneC chan struct{}
+}
+
+// Done returns a channel which is closed once the request has committed.
+func (f *Future) Done() <-chan struct{} {
+	return f.doneC
+}
+
+// Wait blocks until the request has committed, or the context ends.
+func (f *Future) Wait(ctx context.Context) error {
+	select {
+	case <-f.doneC:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// New creates a client, loading its next request number from the
+// ReqNoStore.  If no request number has been stored, the client begins
+// at the low watermark of the supplied client state.
+func New(config *Config) (*Client, error) {
+	if config.ClientState == nil {
+		return nil, errors.New("client state must be set")
+	}
+
+	if len(config.Replicas) < 3*config.F+1 {
+		return nil, errors.Errorf("%d replicas cannot tolerate %d faults", len(config.Replicas), config.F)
+	}
+
+	nextReqNo, ok, err := config.ReqNoStore.LoadNextReqNo()
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not load next request number")
+	}
+
+	if !ok || nextReqNo < config.ClientState.LowWatermark {
+		nextReqNo = config.ClientState.LowWatermark
+	}
+
+	return &Client{
+		config:         config,
+		windowC:        make(chan struct{}),
+		nextReqNo:      nextReqNo,
+		lowWatermark:   config.ClientState.LowWatermark,
+		replicaStates:  map[uint64]*msgs.NetworkState_Client{},
+		pendingCommits: map[uint64]*Future{},
+	}, nil
+}
+
+// ID returns the client's ID.
+func (c *Client) ID() uint64 {
+	return c.config.ClientState.Id
+}
+
+// NextReqNo returns the request number which will be assigned to the next request.
+func (c *Client) NextReqNo() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.nextReqNo
+}
+
+// LowWatermark returns the highest low watermark which at least f+1 replicas
+// have reported for this client.  All requests below it have committed.
+func (c *Client) LowWatermark() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lowWatermark
+}
+
+// Submit assigns the next request number to the data, persists the next
+// request number, and sends the request to every replica.  The network only
+// accepts request numbers within Width of the client's low watermark, so if
+// the window is full, Submit blocks until it advances or the context ends.
+// An error is returned if fewer than f+1 replicas could be sent the request,
+// in which case the request may not commit, but its request number is consumed.
+func (c *Client) Submit(ctx context.Context, data []byte) (*Future, error) {
+	c.mutex.Lock()
+	for c.nextReqNo >= c.lowWatermark+uint64(c.config.ClientState.Width) {
+		windowC := c.windowC
+		c.mutex.Unlock()
+		select {
+		case <-windowC:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c.mutex.Lock()
+	}
+
+	reqNo := c.nextReqNo
+	if err := c.config.ReqNoStore.StoreNextReqNo(reqNo + 1); err != nil {
+		c.mutex.Unlock()
+		return nil, errors.WithMessage(err, "could not persist next request number")
+	}
+	c.nextReqNo++
+
+	future := &Future{
+		ReqNo: reqNo,
+		doneC: make(chan struct{}),
+	}
+	c.pendingCommits[reqNo] = future
+	c.checkCommitted(future)
+	c.mutex.Unlock()
+
+	request := &msgs.Request{
+		ClientId: c.ID(),
+		ReqNo:    reqNo,
+		Data:     data,
+	}
+
+	var sent int
+	var lastErr error
+	for _, replica := range c.config.Replicas {
+		if err := c.config.Transport.Submit(replica, request); err != nil {
+			lastErr = err
+			continue
+		}
+		sent++
+	}
+
+	if sent < c.config.F+1 {
+		return future, errors.WithMessagef(lastErr, "request %d was sent to only %d replicas", reqNo, sent)
+	}
+
+	return future, nil
+}
+
+// ReportClientState records the state of this client, as reported by a
+// replica as of one of its checkpoints.  Any requests which f+1 replicas
+// report committed are resolved, and the window advances to the highest
+// low watermark reported by f+1 replicas.  Stale reports are ignored.
+func (c *Client) ReportClientState(replica uint64, state *msgs.NetworkState_Client) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if state.Id != c.ID() {
+		return
+	}
+
+	if last, ok := c.replicaStates[replica]; ok && last.LowWatermark > state.LowWatermark {
+		return
+	}
+
+	isReplica := false
+	for _, id := range c.config.Replicas {
+		if id == replica {
+			isReplica = true
+			break
+		}
+	}
+	if !isReplica {
+		return
+	}
+
+	c.replicaStates[replica] = state
+
+	lowWatermarks := make([]uint64, 0, len(c.replicaStates))
+	for _, replicaState := range c.replicaStates {
+		lowWatermarks = append(lowWatermarks, replicaState.LowWatermark)
+	}
+
+	if len(lowWatermarks) > c.config.F {
+		// The f+1-th highest low watermark is vouched for by a correct replica
+		sort.Slice(lowWatermarks, func(i, j int) bool {
+			return lowWatermarks[i] > lowWatermarks[j]
+		})
+		if lowWatermark := lowWatermarks[c.config.F]; lowWatermark > c.lowWatermark {
+			c.lowWatermark = lowWatermark
+			close(c.windowC)
+			c.windowC = make(chan struct{})
+		}
+	}
+
+	for _, future := range c.pendingCommits {
+		c.checkCommitted(future)
+	}
+}
+
+// checkCommitted resolves the future if f+1 replicas report its request
+// committed.  The caller must hold the mutex.
+func (c *Client) checkCommitted(future *Future) {
+	committed := 0
+	if future.ReqNo < c.lowWatermark {
+		committed = c.config.F + 1
+	} else {
+		for _, state := range c.replicaStates {
+			if statemachine.IsCommitted(future.ReqNo, state) {
+				committed++
+			}
+		}
+	}
+
+	if committed <= c.config.F {
+		return
+	}
+
+	close(future.doneC)
+	delete(c.pendingCommits, future.ReqNo)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+
+	"github.com/IBM/mirbft/pkg/client"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+)
+
+type fakeTransport struct {
+	mutex     sync.Mutex
+	failing   map[uint64]bool
+	submitted map[uint64][]*msgs.Request
+}
+
+func (ft *fakeTransport) Submit(replica uint64, request *msgs.Request) error {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+	if ft.failing[replica] {
+		return errors.Errorf("replica %d unreachable", replica)
+	}
+	ft.submitted[replica] = append(ft.submitted[replica], request)
+	return nil
+}
+
+func (ft *fakeTransport) reqNos(replica uint64) []uint64 {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+	var result []uint64
+	for _, request := range ft.submitted[replica] {
+		result = append(result, request.ReqNo)
+	}
+	return result
+}
+
+var _ = Describe("Client", func() {
+	var (
+		tmpDir     string
+		transport  *fakeTransport
+		reqNoStore *client.FileReqNoStore
+		config     *client.Config
+		ctx        context.Context
+		cancel     context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "client-test")
+		Expect(err).NotTo(HaveOccurred())
+
+		transport = &fakeTransport{
+			failing:   map[uint64]bool{},
+			submitted: map[uint64][]*msgs.Request{},
+		}
+
+		reqNoStore = &client.FileReqNoStore{
+			Path: filepath.Join(tmpDir, "reqno"),
+		}
+
+		config = &client.Config{
+			ClientState: &msgs.NetworkState_Client{
+				Id:           7,
+				Width:        4,
+				LowWatermark: 0,
+			},
+			Replicas:   []uint64{0, 1, 2, 3},
+			F:          1,
+			Transport:  transport,
+			ReqNoStore: reqNoStore,
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("sends each request to every replica with consecutive request numbers", func() {
+		c, err := client.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := 0; i < 3; i++ {
+			future, err := c.Submit(ctx, []byte("data"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(future.ReqNo).To(Equal(uint64(i)))
+		}
+
+		for _, replica := range config.Replicas {
+			Expect(transport.reqNos(replica)).To(Equal([]uint64{0, 1, 2}))
+		}
+	})
+
+	It("resumes from the persisted request number", func() {
+		c, err := client.New(config)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.Submit(ctx, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.Submit(ctx, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+
+		c, err = client.New(config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.NextReqNo()).To(Equal(uint64(2)))
+	})
+
+	It("resolves a request once f+1 replicas report it committed", func() {
+		c, err := client.New(config)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.Submit(ctx, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+		future, err := c.Submit(ctx, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+
+		committed := &msgs.NetworkState_Client{
+			Id:            7,
+			Width:         4,
+			LowWatermark:  0,
+			CommittedMask: []byte{0x40},
+		}
+
+		c.ReportClientState(0, committed)
+		Consistently(future.Done(), 50*time.Millisecond).ShouldNot(BeClosed())
+
+		By("ignoring reports from non-replicas")
+		c.ReportClientState(9, committed)
+		Consistently(future.Done(), 50*time.Millisecond).ShouldNot(BeClosed())
+
+		c.ReportClientState(2, committed)
+		Expect(future.Wait(ctx)).To(Succeed())
+		Expect(c.LowWatermark()).To(Equal(uint64(0)))
+	})
+
+	It("blocks when the window is full until f+1 replicas advance it", func() {
+		c, err := client.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		futures := []*client.Future{}
+		for i := 0; i < 4; i++ {
+			future, err := c.Submit(ctx, []byte("data"))
+			Expect(err).NotTo(HaveOccurred())
+			futures = append(futures, future)
+		}
+
+		shortCtx, shortCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer shortCancel()
+		_, err = c.Submit(shortCtx, []byte("data"))
+		Expect(err).To(Equal(context.DeadlineExceeded))
+
+		submittedC := make(chan *client.Future)
+		go func() {
+			defer GinkgoRecover()
+			future, err := c.Submit(ctx, []byte("data"))
+			Expect(err).NotTo(HaveOccurred())
+			submittedC <- future
+		}()
+
+		advanced := &msgs.NetworkState_Client{
+			Id:           7,
+			Width:        4,
+			LowWatermark: 2,
+		}
+
+		c.ReportClientState(1, advanced)
+		Consistently(submittedC, 50*time.Millisecond).ShouldNot(Receive())
+
+		c.ReportClientState(3, advanced)
+		var future *client.Future
+		Eventually(submittedC).Should(Receive(&future))
+		// The timed out submission did not consume a request number
+		Expect(future.ReqNo).To(Equal(uint64(4)))
+		Expect(c.LowWatermark()).To(Equal(uint64(2)))
+
+		Expect(futures[0].Done()).To(BeClosed())
+		Expect(futures[1].Done()).To(BeClosed())
+		Expect(futures[2].Done()).NotTo(BeClosed())
+	})
+
+	It("returns an error when fewer than f+1 replicas are reachable", func() {
+		transport.failing[0] = true
+		transport.failing[1] = true
+		transport.failing[2] = true
+
+		c, err := client.New(config)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.Submit(ctx, []byte("data"))
+		Expect(err).To(MatchError("request 0 was sent to only 1 replicas: replica 2 unreachable"))
+		Expect(c.NextReqNo()).To(Equal(uint64(1)))
+	})
+})
@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// FileReqNoStore is a ReqNoStore which stores the next request number in a
+// file.  Each store writes a temporary file, syncs it, and renames it over
+// the previous file, so a crash never leaves a partially written value.
+type FileReqNoStore struct {
+	Path string
+}
+
+func (frs *FileReqNoStore) LoadNextReqNo() (uint64, bool, error) {
+	contents, err := ioutil.ReadFile(frs.Path)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.WithMessagef(err, "could not read %s", frs.Path)
+	}
+
+	if len(contents) != 8 {
+		return 0, false, errors.Errorf("%s has unexpected length %d", frs.Path, len(contents))
+	}
+
+	return binary.BigEndian.Uint64(contents), true, nil
+}
+
+func (frs *FileReqNoStore) StoreNextReqNo(reqNo uint64) error {
+	contents := make([]byte, 8)
+	binary.BigEndian.PutUint64(contents, reqNo)
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(frs.Path), filepath.Base(frs.Path)+".tmp")
+	if err != nil {
+		return errors.WithMessage(err, "could not create temporary file")
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(contents); err != nil {
+		tmpFile.Close()
+		return errors.WithMessagef(err, "could not write %s", tmpFile.Name())
+	}
+
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return errors.WithMessagef(err, "could not sync %s", tmpFile.Name())
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return errors.WithMessagef(err, "could not close %s", tmpFile.Name())
+	}
+
+	if err := os.Rename(tmpFile.Name(), frs.Path); err != nil {
+		return errors.WithMessagef(err, "could not rename %s to %s", tmpFile.Name(), frs.Path)
+	}
+
+	return syncDir(filepath.Dir(frs.Path))
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return errors.WithMessagef(err, "could not open directory %s", path)
+	}
+	defer dir.Close()
+
+	if err := dir.Sync(); err != nil {
+		return errors.WithMessagef(err, "could not sync directory %s", path)
+	}
+
+	return nil
+}
@@ -11,18 +11,117 @@ SPDX-License-Identifier: Apache-2.0
 package reqstore
 
 import (
-	"fmt"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/mirbft"
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 	badger "github.com/dgraph-io/badger/v2"
 	"github.com/pkg/errors"
 )
 
+// Keys are encoded so that they sort by type, then client ID, then request
+// number, allowing a client's entries to be scanned in request number order.
+//
+//	allocation: allocKeyType | clientID (8 bytes, big endian) | reqNo (8 bytes, big endian)
+//	request:    reqKeyType   | clientID (8 bytes, big endian) | reqNo (8 bytes, big endian) | digest
+//
+// Stores written before keys were binary encoded used textual keys of the form
+// "alloc-<clientID>.<reqNo>" and "req-<clientID>.<reqNo>.<hex digest>", these
+// are migrated to the binary encoding when the store is opened.
+const (
+	allocKeyType byte = 1
+	reqKeyType   byte = 2
+
+	prefixLen = 1 + 8
+	keyLen    = prefixLen + 8
+)
+
+func typePrefix(keyType byte) []byte {
+	return []byte{keyType}
+}
+
+func clientPrefix(keyType byte, clientID uint64) []byte {
+	prefix := make([]byte, prefixLen, keyLen)
+	prefix[0] = keyType
+	binary.BigEndian.PutUint64(prefix[1:], clientID)
+	return prefix
+}
+
+func reqNoKey(keyType byte, clientID, reqNo uint64) []byte {
+	key := clientPrefix(keyType, clientID)
+	return append(key, uint64Bytes(reqNo)...)
+}
+
+func uint64Bytes(value uint64) []byte {
+	result := make([]byte, 8)
+	binary.BigEndian.PutUint64(result, value)
+	return result
+}
+
 func reqKey(ack *msgs.RequestAck) []byte {
-	return []byte(fmt.Sprintf("req-%d.%d.%x", ack.ClientId, ack.ReqNo, ack.Digest))
+	return append(reqNoKey(reqKeyType, ack.ClientId, ack.ReqNo), ack.Digest...)
 }
 
 func allocKey(clientID, reqNo uint64) []byte {
-	return []byte(fmt.Sprintf("alloc-%d.%d", clientID, reqNo))
+	return reqNoKey(allocKeyType, clientID, reqNo)
+}
+
+var (
+	legacyAllocPrefix = []byte("alloc-")
+	legacyReqPrefix   = []byte("req-")
+)
+
+// parseLegacyKey decodes the client ID, request number, and (for request
+// keys) digest from a textual key with the given prefix.
+func parseLegacyKey(key, prefix []byte) (clientID, reqNo uint64, digest []byte, err error) {
+	fields := strings.Split(string(key[len(prefix):]), ".")
+	expectedFields := 2
+	if bytes.Equal(prefix, legacyReqPrefix) {
+		expectedFields = 3
+	}
+
+	if len(fields) != expectedFields {
+		return 0, 0, nil, errors.Errorf("legacy key %q is malformed", key)
+	}
+
+	clientID, err = strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, nil, errors.WithMessagef(err, "legacy key %q has invalid client ID", key)
+	}
+
+	reqNo, err = strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, nil, errors.WithMessagef(err, "legacy key %q has invalid request number", key)
+	}
+
+	if expectedFields == 3 && fields[2] != "" {
+		digest, err = hex.DecodeString(fields[2])
+		if err != nil {
+			return 0, 0, nil, errors.WithMessagef(err, "legacy key %q has invalid digest", key)
+		}
+	}
+
+	return clientID, reqNo, digest, nil
+}
+
+// parseKey decodes the client ID, request number, and (for request
+// keys) digest from a key.
+func parseKey(key []byte) (clientID, reqNo uint64, digest []byte, err error) {
+	if len(key) < keyLen {
+		return 0, 0, nil, errors.Errorf("key %x is too short", key)
+	}
+
+	clientID = binary.BigEndian.Uint64(key[1:prefixLen])
+	reqNo = binary.BigEndian.Uint64(key[prefixLen:keyLen])
+	if len(key) > keyLen {
+		digest = append([]byte(nil), key[keyLen:]...)
+	}
+
+	return clientID, reqNo, digest, nil
 }
 
 type Store struct {
@@ -42,9 +141,87 @@ func Open(dirPath string) (*Store, error) {
 		return nil, errors.WithMessage(err, "could not open backing db")
 	}
 
-	return &Store{
+	s := &Store{
 		db: db,
-	}, nil
+	}
+
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, errors.WithMessage(err, "could not migrate legacy keys")
+	}
+
+	return s, nil
+}
+
+// migrate rewrites the entries stored under legacy textual keys with binary
+// keys.  The entries are rewritten with a badger.WriteBatch, so if migration
+// is interrupted, some entries may be stored under both keys, and migration
+// completes when the store is next opened.
+func (s *Store) migrate() error {
+	type legacyEntry struct {
+		oldKey []byte
+		newKey []byte
+		value  []byte
+	}
+
+	var entries []legacyEntry
+	err := s.db.View(func(txn *badger.Txn) error {
+		for _, prefix := range [][]byte{legacyAllocPrefix, legacyReqPrefix} {
+			err := scan(txn, prefix, true, func(item *badger.Item) error {
+				clientID, reqNo, digest, err := parseLegacyKey(item.Key(), prefix)
+				if err != nil {
+					return err
+				}
+
+				newKey := allocKey(clientID, reqNo)
+				if bytes.Equal(prefix, legacyReqPrefix) {
+					newKey = reqKey(&msgs.RequestAck{
+						ClientId: clientID,
+						ReqNo:    reqNo,
+						Digest:   digest,
+					})
+				}
+
+				value, err := item.ValueCopy(nil)
+				if err != nil {
+					return errors.WithMessagef(err, "could not read legacy key %q", item.Key())
+				}
+
+				entries = append(entries, legacyEntry{
+					oldKey: item.KeyCopy(nil),
+					newKey: newKey,
+					value:  value,
+				})
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, entry := range entries {
+		if err := wb.Set(entry.newKey, entry.value); err != nil {
+			return err
+		}
+
+		if err := wb.Delete(entry.oldKey); err != nil {
+			return err
+		}
+	}
+
+	return wb.Flush()
 }
 
 func (s *Store) PutAllocation(clientID, reqNo uint64, digest []byte) error {
@@ -97,12 +274,280 @@ func (s *Store) GetRequest(requestAck *msgs.RequestAck) ([]byte, error) {
 	return valCopy, err
 }
 
+// NewBatch returns a batch which writes its requests and allocations to the
+// store in a single badger transaction, so that they are written atomically.
+// If the writes of the batch exceed the maximum badger transaction size, the
+// put which exceeds it fails, and the batch must be cancelled.
+func (s *Store) NewBatch() mirbft.RequestStoreBatch {
+	return &Batch{
+		txn: s.db.NewTransaction(true),
+	}
+}
+
+// Batch is the mirbft.RequestStoreBatch implementation for Store.
+type Batch struct {
+	txn *badger.Txn
+}
+
+func (b *Batch) PutAllocation(clientID, reqNo uint64, digest []byte) error {
+	return b.set(allocKey(clientID, reqNo), digest)
+}
+
+func (b *Batch) PutRequest(requestAck *msgs.RequestAck, data []byte) error {
+	return b.set(reqKey(requestAck), data)
+}
+
+func (b *Batch) set(key, value []byte) error {
+	err := b.txn.Set(key, value)
+	if err == badger.ErrTxnTooBig {
+		return errors.WithMessage(err, "batch exceeds the maximum transaction size")
+	}
+	return err
+}
+
+// Write commits the batch to the store.  The batch may not be used afterwards.
+func (b *Batch) Write() error {
+	return b.txn.Commit()
+}
+
+// Cancel discards the batch, it has no effect after Write.
+func (b *Batch) Cancel() {
+	b.txn.Discard()
+}
+
+// Commit deletes the data of a committed request, keeping its allocation.
+// The ClientProcessor does not invoke it, as the data of committed requests
+// must remain available to forward to lagging replicas until the requests
+// fall below their client's low watermark, and are garbage collected.
 func (s *Store) Commit(ack *msgs.RequestAck) error {
 	return s.db.Update(func(txn *badger.Txn) error {
 		return txn.Delete(reqKey(ack))
 	})
 }
 
+// GarbageCollect deletes the allocations and requests of each client whose
+// request numbers are below the client's low watermark.  It should be invoked
+// with the client states of a checkpoint, as below the low watermark, all
+// requests are committed.  The ClientProcessor invokes it with the client
+// states of the checkpoint preceding each new checkpoint.  The deletions are
+// committed in a single transaction, unless it would grow too large, in which
+// case it is split, so a failure may leave some of them undone, which
+// a subsequent collection completes.  The number of entries whose deletion was
+// committed is returned, even when an error is.
+func (s *Store) GarbageCollect(clients []*msgs.NetworkState_Client) (int, error) {
+	var keys [][]byte
+	err := s.db.View(func(txn *badger.Txn) error {
+		for _, client := range clients {
+			for _, keyType := range []byte{allocKeyType, reqKeyType} {
+				err := scan(txn, clientPrefix(keyType, client.Id), false, func(item *badger.Item) error {
+					_, reqNo, _, err := parseKey(item.Key())
+					if err != nil {
+						return err
+					}
+
+					if reqNo >= client.LowWatermark {
+						// Keys are ordered by request number, so no more may be collected
+						return errStopScan
+					}
+
+					keys = append(keys, item.KeyCopy(nil))
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return s.deleteKeys(keys)
+}
+
+// RemoveClient deletes all of the allocations and requests of the given
+// client.  It should be invoked once the client has been removed from the
+// network by a reconfiguration, as its requests may no longer commit.  As
+// with GarbageCollect, the deletions are split into several transactions
+// only if a single transaction would grow too large.
+func (s *Store) RemoveClient(clientID uint64) error {
+	var keys [][]byte
+	err := s.db.View(func(txn *badger.Txn) error {
+		for _, keyType := range []byte{allocKeyType, reqKeyType} {
+			err := scan(txn, clientPrefix(keyType, clientID), false, func(item *badger.Item) error {
+				keys = append(keys, item.KeyCopy(nil))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.deleteKeys(keys)
+	return err
+}
+
+// deleteKeys deletes the keys in a single transaction.  Only if the
+// transaction would grow too large is it committed early, and the remaining
+// keys deleted in a new transaction, so that any number of keys may be
+// deleted.  The number of keys whose deletion was committed is returned.
+func (s *Store) deleteKeys(keys [][]byte) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	txn := s.db.NewTransaction(true)
+	defer func() {
+		txn.Discard()
+	}()
+
+	committed, pending := 0, 0
+	for _, key := range keys {
+		err := txn.Delete(key)
+		if err == badger.ErrTxnTooBig {
+			if err := txn.Commit(); err != nil {
+				return committed, errors.WithMessage(err, "could not commit deletions")
+			}
+			committed += pending
+			pending = 0
+
+			txn = s.db.NewTransaction(true)
+			err = txn.Delete(key)
+		}
+		if err != nil {
+			return committed, errors.WithMessagef(err, "could not delete key %x", key)
+		}
+		pending++
+	}
+
+	if err := txn.Commit(); err != nil {
+		return committed, errors.WithMessage(err, "could not commit deletions")
+	}
+
+	return committed + pending, nil
+}
+
+// Uncommitted invokes forEach with the ack of every stored request of the
+// given clients whose request number is at or above its client's low
+// watermark, ordered by client ID, then request number.  The size of each ack
+// is the size of the stored request data.  The clients should be the client
+// states of the latest checkpoint, as requests below the low watermarks are
+// committed, but may not yet have been garbage collected, and requests of
+// clients no longer in the network will never commit.  The store does not
+// track commits above the low watermarks, so the requests committed since the
+// checkpoint are included.  It is intended for recovery, to re-inject
+// RequestPersisted events after a restart.  If forEach returns an error,
+// iteration stops, and the error is returned.
+func (s *Store) Uncommitted(clients []*msgs.NetworkState_Client, forEach func(*msgs.RequestAck) error) error {
+	lowWatermarks := make(map[uint64]uint64, len(clients))
+	for _, client := range clients {
+		lowWatermarks[client.Id] = client.LowWatermark
+	}
+
+	return s.scanRequests(typePrefix(reqKeyType), func(ack *msgs.RequestAck) error {
+		lowWatermark, ok := lowWatermarks[ack.ClientId]
+		if !ok || ack.ReqNo < lowWatermark {
+			return nil
+		}
+
+		return forEach(ack)
+	})
+}
+
+// ClientUncommitted is like Uncommitted, but only for the requests
+// of the given client.
+func (s *Store) ClientUncommitted(client *msgs.NetworkState_Client, forEach func(*msgs.RequestAck) error) error {
+	return s.scanRequests(clientPrefix(reqKeyType, client.Id), func(ack *msgs.RequestAck) error {
+		if ack.ReqNo < client.LowWatermark {
+			return nil
+		}
+
+		return forEach(ack)
+	})
+}
+
+// Allocations invokes forEach with the client ID, request number, and digest
+// of every stored allocation, ordered by client ID, then request number.
+// If forEach returns an error, iteration stops, and the error is returned.
+func (s *Store) Allocations(forEach func(clientID, reqNo uint64, digest []byte) error) error {
+	return s.scanAllocations(typePrefix(allocKeyType), forEach)
+}
+
+// ClientAllocations is like Allocations, but only for the allocations
+// of the given client.
+func (s *Store) ClientAllocations(clientID uint64, forEach func(clientID, reqNo uint64, digest []byte) error) error {
+	return s.scanAllocations(clientPrefix(allocKeyType, clientID), forEach)
+}
+
+func (s *Store) scanRequests(prefix []byte, forEach func(*msgs.RequestAck) error) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		return scan(txn, prefix, false, func(item *badger.Item) error {
+			clientID, reqNo, digest, err := parseKey(item.Key())
+			if err != nil {
+				return err
+			}
+
+			return forEach(&msgs.RequestAck{
+				ClientId: clientID,
+				ReqNo:    reqNo,
+				Digest:   digest,
+				Size:     uint32(item.ValueSize()),
+			})
+		})
+	})
+}
+
+func (s *Store) scanAllocations(prefix []byte, forEach func(clientID, reqNo uint64, digest []byte) error) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		return scan(txn, prefix, true, func(item *badger.Item) error {
+			clientID, reqNo, _, err := parseKey(item.Key())
+			if err != nil {
+				return err
+			}
+
+			digest, err := item.ValueCopy(nil)
+			if err != nil {
+				return errors.WithMessagef(err, "could not read allocation %d.%d", clientID, reqNo)
+			}
+
+			return forEach(clientID, reqNo, digest)
+		})
+	})
+}
+
+// scan invokes forEach for each item whose key has the given prefix, in key
+// order.  If forEach returns errStopScan, the scan ends without error.
+func scan(txn *badger.Txn, prefix []byte, prefetchValues bool, forEach func(*badger.Item) error) error {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = prefetchValues
+	opts.Prefix = prefix
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		err := forEach(it.Item())
+		if err == errStopScan {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var errStopScan = errors.New("stop scan")
+
 func (s *Store) Sync() error {
 	return s.db.Sync()
 }
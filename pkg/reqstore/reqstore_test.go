@@ -1,6 +1,7 @@
 package reqstore_test
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 
@@ -9,8 +10,16 @@ import (
 
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 	"github.com/IBM/mirbft/pkg/reqstore"
+	badger "github.com/dgraph-io/badger/v2"
+	"google.golang.org/protobuf/proto"
 )
 
+func withSize(ack *msgs.RequestAck, size uint32) *msgs.RequestAck {
+	ack = proto.Clone(ack).(*msgs.RequestAck)
+	ack.Size = size
+	return ack
+}
+
 var _ = Describe("Reqstore", func() {
 	var (
 		tmpDir   string
@@ -83,8 +92,258 @@ var _ = Describe("Reqstore", func() {
 		os.RemoveAll(tmpDir)
 	})
 
+	It("garbage collects allocations and requests below the low watermarks", func() {
+		for _, ack := range []*msgs.RequestAck{ack1dot1, ack1dot2, ack1dot3, ack2dot1, ack2dot2} {
+			err := reqStore.PutAllocation(ack.ClientId, ack.ReqNo, ack.Digest)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		clients := []*msgs.NetworkState_Client{
+			{
+				Id:           1,
+				LowWatermark: 3,
+			},
+			{
+				Id:           2,
+				LowWatermark: 2,
+			},
+		}
+
+		reclaimed, err := reqStore.GarbageCollect(clients)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reclaimed).To(Equal(4))
+
+		digest, err := reqStore.GetAllocation(1, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(BeNil())
+
+		digest, err = reqStore.GetAllocation(1, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal([]byte("digest1")))
+
+		data, err := reqStore.GetRequest(ack2dot1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(BeNil())
+
+		data, err = reqStore.GetRequest(ack2dot2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte("data2dot2")))
+
+		reclaimed, err = reqStore.GarbageCollect(clients)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reclaimed).To(Equal(0))
+	})
+
+	It("splits garbage collections too large for a single transaction", func() {
+		// Enough deletions to exceed the maximum transaction size
+		const count = 200000
+		for reqNo := uint64(0); reqNo < count; reqNo += 10000 {
+			batch := reqStore.NewBatch()
+			for i := reqNo; i < reqNo+10000; i++ {
+				Expect(batch.PutAllocation(3, i, []byte("digest"))).To(Succeed())
+			}
+			Expect(batch.Write()).To(Succeed())
+		}
+
+		reclaimed, err := reqStore.GarbageCollect([]*msgs.NetworkState_Client{
+			{
+				Id:           3,
+				LowWatermark: count,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reclaimed).To(Equal(count))
+
+		allocations := 0
+		err = reqStore.ClientAllocations(3, func(uint64, uint64, []byte) error {
+			allocations++
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allocations).To(Equal(0))
+	})
+
+	It("removes all allocations and requests of a removed client", func() {
+		for _, ack := range []*msgs.RequestAck{ack1dot3, ack2dot1, ack2dot2} {
+			err := reqStore.PutAllocation(ack.ClientId, ack.ReqNo, ack.Digest)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(reqStore.RemoveClient(2)).To(Succeed())
+
+		digest, err := reqStore.GetAllocation(2, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(BeNil())
+
+		data, err := reqStore.GetRequest(ack2dot2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(BeNil())
+
+		digest, err = reqStore.GetAllocation(1, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal([]byte("digest1")))
+
+		data, err = reqStore.GetRequest(ack1dot3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte("data1dot3")))
+	})
+
+	It("writes batches atomically on Write", func() {
+		ack := &msgs.RequestAck{
+			ClientId: 3,
+			ReqNo:    1,
+			Digest:   []byte("digest3"),
+		}
+
+		batch := reqStore.NewBatch()
+		Expect(batch.PutRequest(ack, []byte("data3dot1"))).To(Succeed())
+		Expect(batch.PutAllocation(3, 1, []byte("digest3"))).To(Succeed())
+
+		digest, err := reqStore.GetAllocation(3, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(BeNil())
+
+		Expect(batch.Write()).To(Succeed())
+
+		digest, err = reqStore.GetAllocation(3, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal([]byte("digest3")))
+
+		data, err := reqStore.GetRequest(ack)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte("data3dot1")))
+	})
+
+	It("discards cancelled batches", func() {
+		batch := reqStore.NewBatch()
+		Expect(batch.PutAllocation(3, 1, []byte("digest3"))).To(Succeed())
+		batch.Cancel()
+
+		digest, err := reqStore.GetAllocation(3, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(BeNil())
+
+		By("ignoring a cancel after the write")
+		batch = reqStore.NewBatch()
+		Expect(batch.PutAllocation(3, 1, []byte("digest3"))).To(Succeed())
+		Expect(batch.Write()).To(Succeed())
+		batch.Cancel()
+
+		digest, err = reqStore.GetAllocation(3, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal([]byte("digest3")))
+	})
+
 	It("returns all uncommitted txes", func() {
-		_ = reqStore
-		// XXX need to actually test this
+		var acks []*msgs.RequestAck
+		err := reqStore.Uncommitted([]*msgs.NetworkState_Client{{Id: 1}, {Id: 2}}, func(ack *msgs.RequestAck) error {
+			acks = append(acks, ack)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acks).To(HaveLen(3))
+		Expect(proto.Equal(acks[0], withSize(ack1dot3, 9))).To(BeTrue())
+		Expect(proto.Equal(acks[1], withSize(ack2dot1, 9))).To(BeTrue())
+		Expect(proto.Equal(acks[2], withSize(ack2dot2, 9))).To(BeTrue())
+
+		acks = nil
+		err = reqStore.ClientUncommitted(&msgs.NetworkState_Client{Id: 2}, func(ack *msgs.RequestAck) error {
+			acks = append(acks, ack)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acks).To(HaveLen(2))
+		Expect(proto.Equal(acks[0], withSize(ack2dot1, 9))).To(BeTrue())
+		Expect(proto.Equal(acks[1], withSize(ack2dot2, 9))).To(BeTrue())
+	})
+
+	It("skips requests below the low watermark or of unknown clients", func() {
+		clients := []*msgs.NetworkState_Client{
+			{
+				Id:           2,
+				LowWatermark: 2,
+			},
+		}
+
+		var acks []*msgs.RequestAck
+		err := reqStore.Uncommitted(clients, func(ack *msgs.RequestAck) error {
+			acks = append(acks, ack)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acks).To(HaveLen(1))
+		Expect(proto.Equal(acks[0], withSize(ack2dot2, 9))).To(BeTrue())
+
+		acks = nil
+		err = reqStore.ClientUncommitted(clients[0], func(ack *msgs.RequestAck) error {
+			acks = append(acks, ack)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acks).To(HaveLen(1))
+		Expect(proto.Equal(acks[0], withSize(ack2dot2, 9))).To(BeTrue())
+	})
+
+	It("returns allocations in request number order", func() {
+		for reqNo := uint64(300); reqNo >= 1; reqNo -= 100 {
+			err := reqStore.PutAllocation(1, reqNo, []byte{byte(reqNo)})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		err := reqStore.PutAllocation(2, 1, []byte("other-client"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var reqNos []uint64
+		err = reqStore.ClientAllocations(1, func(clientID, reqNo uint64, digest []byte) error {
+			Expect(clientID).To(Equal(uint64(1)))
+			Expect(digest).To(Equal([]byte{byte(reqNo)}))
+			reqNos = append(reqNos, reqNo)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reqNos).To(Equal([]uint64{100, 200, 300}))
+
+		count := 0
+		err = reqStore.Allocations(func(clientID, reqNo uint64, digest []byte) error {
+			count++
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(4))
+	})
+
+	It("migrates entries stored under legacy keys", func() {
+		reqStore.Close()
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+
+		db, err := badger.Open(badger.DefaultOptions(tmpDir).WithLogger(nil))
+		Expect(err).NotTo(HaveOccurred())
+		err = db.Update(func(txn *badger.Txn) error {
+			if err := txn.Set([]byte("alloc-3.7"), []byte("digest")); err != nil {
+				return err
+			}
+			return txn.Set([]byte(fmt.Sprintf("req-3.7.%x", []byte("digest"))), []byte("data"))
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Close()).To(Succeed())
+
+		reqStore, err = reqstore.Open(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		digest, err := reqStore.GetAllocation(3, 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal([]byte("digest")))
+
+		data, err := reqStore.GetRequest(&msgs.RequestAck{ClientId: 3, ReqNo: 7, Digest: digest})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte("data")))
+
+		var acks []*msgs.RequestAck
+		err = reqStore.Uncommitted([]*msgs.NetworkState_Client{{Id: 3}}, func(ack *msgs.RequestAck) error {
+			acks = append(acks, ack)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acks).To(HaveLen(1))
+		Expect(acks[0].Size).To(Equal(uint32(4)))
 	})
 })
@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simplewal
+
+import (
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the buckets of each LatencyHistogram,
+// doubling from 50us to roughly 1.6s.  Latencies above the final bound are
+// counted in an additional overflow bucket.
+var LatencyBuckets = func() []time.Duration {
+	buckets := make([]time.Duration, 16)
+	for i := range buckets {
+		buckets[i] = (50 * time.Microsecond) << uint(i)
+	}
+	return buckets
+}()
+
+// LatencyHistogram is a snapshot of the distribution of observed latencies.
+type LatencyHistogram struct {
+	// Counts has one entry per bucket of LatencyBuckets, plus a final
+	// entry counting the latencies which exceeded every bucket.
+	Counts []uint64
+
+	// Count is the total number of latencies observed.
+	Count uint64
+
+	// Sum is the total of the latencies observed.
+	Sum time.Duration
+}
+
+// Mean returns the average latency observed, or zero if none were.
+func (lh LatencyHistogram) Mean() time.Duration {
+	if lh.Count == 0 {
+		return 0
+	}
+	return lh.Sum / time.Duration(lh.Count)
+}
+
+type histogram struct {
+	mutex sync.Mutex
+	lh    LatencyHistogram
+}
+
+func newHistogram() *histogram {
+	return &histogram{
+		lh: LatencyHistogram{
+			Counts: make([]uint64, len(LatencyBuckets)+1),
+		},
+	}
+}
+
+func (h *histogram) observe(latency time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	i := 0
+	for i < len(LatencyBuckets) && latency > LatencyBuckets[i] {
+		i++
+	}
+
+	h.lh.Counts[i]++
+	h.lh.Count++
+	h.lh.Sum += latency
+}
+
+func (h *histogram) snapshot() LatencyHistogram {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	result := h.lh
+	result.Counts = append([]uint64(nil), h.lh.Counts...)
+	return result
+}
@@ -5,12 +5,33 @@ SPDX-License-Identifier: Apache-2.0
 */
 
 // Package simplewal is a basic WAL implementation meant to be the first 'real' WAL
-// option for mirbft.  More sophisticated WALs with checksums, byte alignments, etc.
-// may be produced in the future, but this is just a simple place to start.
+// option for mirbft.  The log is stored in segment files, each entry of which
+// is prefixed by a format marker and a CRC-32C checksum of its contents, so that
+// corruption is detected when the log is loaded.  Entries written before
+// checksums were introduced have no marker, and are still loaded, unverified.
+//
+// A crash while writing may leave a torn write at the end of the log, either as
+// a partial entry, or as entries which fail their checksum.  Because entries are
+// only ever appended, such a tail never contains acknowledged (synced) data, and
+// it is discarded automatically when the log is opened or loaded.  Corruption
+// anywhere else in the log, including a log none of whose entries are valid,
+// cannot be repaired safely, and is reported as a *CorruptionError, no entry is
+// ever skipped.
 package simplewal
 
 import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 
@@ -19,25 +40,263 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+const (
+	// formatMarker is the first byte of every checksummed entry.  A marshaled
+	// entry never begins with a zero byte, as it would encode field number
+	// zero, so entries written before checksums were introduced, which are
+	// only the marshaled entry, are distinguished by its absence.
+	formatMarker = 0
+
+	// formatVersion is the second byte of every checksummed entry.
+	formatVersion = 1
+
+	// headerSize is the number of bytes of format marker, version, and
+	// checksum which precede each checksummed entry.
+	headerSize = 6
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// CorruptionError indicates that the log is corrupt, somewhere other than a
+// torn write at its tail, and must be repaired or restored by the operator.
+type CorruptionError struct {
+	// Index is the index of the corrupt entry, if known.
+	Index uint64
+
+	// Segment is the path of the corrupt segment file, if known.
+	Segment string
+
+	// Reason describes the corruption.
+	Reason string
+}
+
+func (ce *CorruptionError) Error() string {
+	switch {
+	case ce.Index != 0:
+		return fmt.Sprintf("WAL corrupt at index %d: %s", ce.Index, ce.Reason)
+	case ce.Segment != "":
+		return fmt.Sprintf("WAL corrupt in segment %s: %s", ce.Segment, ce.Reason)
+	default:
+		return fmt.Sprintf("WAL corrupt: %s", ce.Reason)
+	}
+}
+
+// TailRepair describes a torn write which was discarded from the end of the log.
+type TailRepair struct {
+	// FirstIndex is the index of the first discarded entry.
+	FirstIndex uint64
+
+	// Reason describes why the entries were discarded.
+	Reason string
+}
+
+// SyncMode determines how calls to Sync are translated into fsyncs.
+type SyncMode int
+
+const (
+	// SyncPerBatch performs an fsync for every call to Sync, that is, once
+	// for every batch of actions processed.  This is the default.
+	SyncPerBatch SyncMode = iota
+
+	// GroupCommit allows concurrent calls to Sync to share a single fsync.  The
+	// first caller waits for Options.GroupCommitDelay before performing the
+	// fsync, and any callers which arrive during the delay or while the fsync
+	// is in progress are satisfied by it, or by the next one.  Sync still never
+	// returns until all writes which preceded it are durable, and if no writes
+	// have occurred since the last fsync, it returns immediately.  This is
+	// useful when several WAL writers, for instance, several processors
+	// sharing a log, sync concurrently.
+	GroupCommit
+)
+
+// Options configures the durability behavior of a WAL.
+type Options struct {
+	// SyncMode selects between per batch and group commit syncing.
+	SyncMode SyncMode
+
+	// GroupCommitDelay is how long an fsync is delayed to allow other writes
+	// to join it when SyncMode is GroupCommit.  A longer delay increases the
+	// number of writes sharing each fsync, at the expense of latency.
+	GroupCommitDelay time.Duration
+}
+
+// Stats reports the latency of the WAL's disk operations.
+type Stats struct {
+	// Write is the distribution of latencies of Write, excluding encoding.
+	Write LatencyHistogram
+
+	// Sync is the distribution of latencies of the fsyncs performed.
+	Sync LatencyHistogram
+
+	// SyncCalls is the number of times Sync was invoked.  In GroupCommit
+	// mode, this exceeds Sync.Count when calls have shared an fsync.
+	SyncCalls uint64
+}
+
 type WAL struct {
-	mutex sync.Mutex
-	log   *wal.Log
+	mutex   sync.Mutex
+	log     *wal.Log
+	repairs []*TailRepair
+	opts    Options
+
+	writeLatency *histogram
+	syncLatency  *histogram
+
+	// The following are protected by syncMutex
+	syncMutex sync.Mutex
+	syncCond  *sync.Cond
+	syncing   bool
+	syncErr   error
+	syncCalls uint64
+	syncedSeq uint64
+	writeSeq  uint64
 }
 
+// Open opens the WAL at the given path with the default options.
 func Open(path string) (*WAL, error) {
-	log, err := wal.Open(path, &wal.Options{
+	return OpenWithOptions(path, &Options{})
+}
+
+// OpenWithOptions opens the WAL at the given path, creating it if it does not
+// exist.  If the final segment ends with a partial entry, the partial entry
+// is truncated.
+func OpenWithOptions(path string, opts *Options) (*WAL, error) {
+	var repairs []*TailRepair
+
+	log, err := openLog(path)
+	if err == wal.ErrCorrupt {
+		var repair *TailRepair
+		repair, err = repairLastSegment(path)
+		if err != nil {
+			return nil, err
+		}
+		repairs = append(repairs, repair)
+
+		log, err = openLog(path)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not open WAL")
+	}
+
+	w := &WAL{
+		log:          log,
+		repairs:      repairs,
+		opts:         *opts,
+		writeLatency: newHistogram(),
+		syncLatency:  newHistogram(),
+	}
+	w.syncCond = sync.NewCond(&w.syncMutex)
+
+	return w, nil
+}
+
+func openLog(path string) (*wal.Log, error) {
+	return wal.Open(path, &wal.Options{
 		NoSync: true,
 		NoCopy: true,
 	})
+}
+
+// repairLastSegment truncates a partial entry from the end of the last segment
+// file.  The segment format is that of the underlying log, a uvarint length
+// followed by the entry.
+func repairLastSegment(path string) (*TailRepair, error) {
+	segments, err := listSegments(path)
 	if err != nil {
-		return nil, errors.WithMessage(err, "could not open WAL")
+		return nil, err
+	}
+
+	if len(segments) == 0 {
+		return nil, &CorruptionError{Reason: "log reported corrupt, but contains no segments"}
+	}
+
+	lastSegment := segments[len(segments)-1]
+	segmentPath := filepath.Join(path, lastSegment)
+	firstIndex, _ := strconv.ParseUint(lastSegment[:20], 10, 64)
+
+	f, err := os.OpenFile(segmentPath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not open last segment")
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	var entries uint64
+	for {
+		size, err := binary.ReadUvarint(reader)
+		if err == io.EOF {
+			// The segment is intact, so the corruption lies elsewhere.
+			return nil, &CorruptionError{
+				Segment: segmentPath,
+				Reason:  "log reported corrupt, but the last segment contains no partial entry",
+			}
+		}
+		if err != nil {
+			break
+		}
+
+		n, err := reader.Discard(int(size))
+		if err != nil || uint64(n) != size {
+			break
+		}
+
+		offset += int64(uvarintSize(size)) + int64(size)
+		entries++
+	}
+
+	if err := f.Truncate(offset); err != nil {
+		return nil, errors.WithMessage(err, "could not truncate partial entry")
+	}
+
+	if err := f.Sync(); err != nil {
+		return nil, errors.WithMessage(err, "could not sync truncated segment")
 	}
 
-	return &WAL{
-		log: log,
+	return &TailRepair{
+		FirstIndex: firstIndex + entries,
+		Reason:     "partial entry at end of segment " + segmentPath,
 	}, nil
 }
 
+// listSegments returns the names of the segment files of the log, in order.
+func listSegments(path string) ([]string, error) {
+	fis, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not list WAL segments")
+	}
+
+	var segments []string
+	for _, fi := range fis {
+		// Segment files are named by their 20 digit first index, possibly with
+		// a suffix, if the log was interrupted while truncating.
+		if fi.IsDir() || len(fi.Name()) < 20 {
+			continue
+		}
+		if _, err := strconv.ParseUint(fi.Name()[:20], 10, 64); err != nil {
+			continue
+		}
+		segments = append(segments, fi.Name())
+	}
+
+	sort.Strings(segments)
+
+	return segments, nil
+}
+
+func uvarintSize(x uint64) int {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return binary.PutUvarint(buf, x)
+}
+
+// Repairs returns the torn writes which have been discarded from the
+// tail of the log since it was opened, if any.
+func (w *WAL) Repairs() []*TailRepair {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.repairs
+}
+
 func (w *WAL) IsEmpty() (bool, error) {
 	firstIndex, err := w.log.FirstIndex()
 	if err != nil {
@@ -47,6 +306,11 @@ func (w *WAL) IsEmpty() (bool, error) {
 	return firstIndex == 0, nil
 }
 
+// LoadAll verifies each entry of the log and invokes forEach on it.  If the
+// final entries of the log fail verification, and no valid entry follows them,
+// they are a torn write and are truncated from the log.  Otherwise, if any
+// entry fails verification, including when no entry of the log is valid, a
+// *CorruptionError is returned before forEach is invoked for any entry.
 func (w *WAL) LoadAll(forEach func(index uint64, p *msgs.Persistent)) error {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
@@ -62,46 +326,251 @@ func (w *WAL) LoadAll(forEach func(index uint64, p *msgs.Persistent)) error {
 
 	lastIndex, err := w.log.LastIndex()
 	if err != nil {
-		return errors.WithMessage(err, "could not read first index")
+		return errors.WithMessage(err, "could not read last index")
 	}
 
+	// Verify the whole log before invoking the callback, so that corrupt
+	// entries are never partially applied.
+	entries := make([]*msgs.Persistent, 0, lastIndex-firstIndex+1)
+	var firstBad uint64
+	var firstBadErr error
 	for i := firstIndex; i <= lastIndex; i++ {
 		data, err := w.log.Read(i)
 		if err != nil {
 			return errors.WithMessagef(err, "could not read index %d", i)
 		}
 
-		result := &msgs.Persistent{}
-		err = proto.Unmarshal(data, result)
+		result, err := decode(data)
 		if err != nil {
-			return errors.WithMessage(err, "error decoding to proto, is the WAL corrupt?")
+			if firstBad == 0 {
+				firstBad = i
+				firstBadErr = err
+			}
+			continue
+		}
+
+		if firstBad != 0 {
+			// A valid entry follows an invalid one, so this is not a torn write.
+			return &CorruptionError{
+				Index:  firstBad,
+				Reason: firstBadErr.Error(),
+			}
+		}
+
+		entries = append(entries, result)
+	}
+
+	if firstBad != 0 {
+		if firstBad == firstIndex {
+			// Without a valid entry preceding the invalid ones, we cannot
+			// know that none of them were synced, so this is not treated
+			// as a torn write.
+			return &CorruptionError{
+				Index:  firstBad,
+				Reason: "no valid entries in log: " + firstBadErr.Error(),
+			}
 		}
 
-		forEach(i, result)
+		if err := w.log.TruncateBack(firstBad - 1); err != nil {
+			return errors.WithMessagef(err, "could not truncate torn write beginning at index %d", firstBad)
+		}
+
+		if err := w.log.Sync(); err != nil {
+			return errors.WithMessage(err, "could not sync truncated log")
+		}
+
+		w.repairs = append(w.repairs, &TailRepair{
+			FirstIndex: firstBad,
+			Reason:     firstBadErr.Error(),
+		})
+	}
+
+	for i, entry := range entries {
+		forEach(firstIndex+uint64(i), entry)
 	}
 
 	return nil
 }
 
+func decode(data []byte) (*msgs.Persistent, error) {
+	if len(data) == 0 {
+		return nil, errors.New("entry is empty")
+	}
+
+	if data[0] != formatMarker {
+		return decodeLegacy(data)
+	}
+
+	if len(data) < headerSize {
+		return nil, errors.Errorf("entry of %d bytes is too short to contain a checksum", len(data))
+	}
+
+	if data[1] != formatVersion {
+		return nil, errors.Errorf("entry has unknown format version %d", data[1])
+	}
+
+	expected := binary.BigEndian.Uint32(data[2:headerSize])
+	actual := crc32.Checksum(data[headerSize:], crcTable)
+	if expected != actual {
+		return nil, errors.Errorf("entry checksum %08x does not match computed checksum %08x", expected, actual)
+	}
+
+	result := &msgs.Persistent{}
+	err := proto.Unmarshal(data[headerSize:], result)
+	if err != nil {
+		return nil, errors.WithMessage(err, "entry checksum matches, but entry could not be decoded")
+	}
+
+	return result, nil
+}
+
+// decodeLegacy decodes an entry written before checksums were introduced,
+// which is only the marshaled entry, and so cannot be verified beyond
+// being decodable.
+func decodeLegacy(data []byte) (*msgs.Persistent, error) {
+	result := &msgs.Persistent{}
+	err := proto.Unmarshal(data, result)
+	if err != nil {
+		return nil, errors.WithMessage(err, "entry has no checksum and could not be decoded")
+	}
+
+	if result.Type == nil {
+		return nil, errors.New("entry has no checksum and decodes to an empty entry")
+	}
+
+	return result, nil
+}
+
+func encode(p *msgs.Persistent) ([]byte, error) {
+	header := make([]byte, headerSize)
+	header[0] = formatMarker
+	header[1] = formatVersion
+
+	data, err := proto.MarshalOptions{}.MarshalAppend(header, p)
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not marshal")
+	}
+
+	binary.BigEndian.PutUint32(data[2:headerSize], crc32.Checksum(data[headerSize:], crcTable))
+
+	return data, nil
+}
+
 func (w *WAL) Write(index uint64, p *msgs.Persistent) error {
-	data, err := proto.Marshal(p)
+	data, err := encode(p)
 	if err != nil {
-		return errors.WithMessage(err, "could not marshal")
+		return err
 	}
 
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	return w.log.Write(index, data)
+
+	start := time.Now()
+	if err := w.log.Write(index, data); err != nil {
+		return err
+	}
+	w.writeLatency.observe(time.Since(start))
+
+	w.wrote()
+	return nil
 }
 
 func (w *WAL) Truncate(index uint64) error {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	return w.log.TruncateFront(index)
+	if err := w.log.TruncateFront(index); err != nil {
+		return err
+	}
+
+	w.wrote()
+	return nil
+}
+
+// wrote records that a write has occurred which must be synced.
+func (w *WAL) wrote() {
+	w.syncMutex.Lock()
+	w.writeSeq++
+	w.syncMutex.Unlock()
 }
 
+// Sync returns once all preceding writes are durable, according to the
+// configured SyncMode.  If an fsync fails, the state of the log on disk is
+// unknown, so the error is returned for that and every subsequent call.
 func (w *WAL) Sync() error {
-	return w.log.Sync()
+	w.syncMutex.Lock()
+	defer w.syncMutex.Unlock()
+
+	w.syncCalls++
+
+	if w.opts.SyncMode != GroupCommit {
+		for w.syncing {
+			w.syncCond.Wait()
+		}
+		w.syncing = true
+		w.fsync(w.writeSeq)
+		w.syncing = false
+		w.syncCond.Broadcast()
+		return w.syncErr
+	}
+
+	target := w.writeSeq
+	for w.syncErr == nil && w.syncedSeq < target {
+		if w.syncing {
+			// Another caller is leading an fsync, we will either be
+			// satisfied by it, or lead the next one.
+			w.syncCond.Wait()
+			continue
+		}
+
+		w.syncing = true
+		if w.opts.GroupCommitDelay > 0 {
+			w.syncMutex.Unlock()
+			time.Sleep(w.opts.GroupCommitDelay)
+			w.syncMutex.Lock()
+		}
+		w.fsync(w.writeSeq)
+		w.syncing = false
+		w.syncCond.Broadcast()
+	}
+
+	return w.syncErr
+}
+
+// fsync syncs the log, which makes durable all writes up to and including
+// seq.  The syncMutex must be held, and is released for the duration of the
+// fsync itself.
+func (w *WAL) fsync(seq uint64) {
+	if w.syncErr != nil {
+		return
+	}
+
+	w.syncMutex.Unlock()
+	start := time.Now()
+	err := w.log.Sync()
+	w.syncLatency.observe(time.Since(start))
+	w.syncMutex.Lock()
+
+	if err != nil {
+		w.syncErr = errors.WithMessage(err, "fsync failed")
+		return
+	}
+
+	if seq > w.syncedSeq {
+		w.syncedSeq = seq
+	}
+}
+
+// Stats returns a snapshot of the latencies of the WAL's disk operations.
+func (w *WAL) Stats() *Stats {
+	w.syncMutex.Lock()
+	syncCalls := w.syncCalls
+	w.syncMutex.Unlock()
+
+	return &Stats{
+		Write:     w.writeLatency.snapshot(),
+		Sync:      w.syncLatency.snapshot(),
+		SyncCalls: syncCalls,
+	}
 }
 
 func (w *WAL) Close() error {
@@ -0,0 +1,293 @@
+package simplewal_test
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	tidwall "github.com/tidwall/wal"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/simplewal"
+)
+
+var _ = Describe("WAL", func() {
+	var (
+		tmpDir      string
+		segmentPath string
+		wal         *simplewal.WAL
+	)
+
+	entry := func(seqNo uint64) *msgs.Persistent {
+		return &msgs.Persistent{
+			Type: &msgs.Persistent_PEntry{
+				PEntry: &msgs.PEntry{
+					SeqNo:  seqNo,
+					Digest: []byte("digest"),
+				},
+			},
+		}
+	}
+
+	loadAll := func() ([]uint64, error) {
+		var seqNos []uint64
+		err := wal.LoadAll(func(index uint64, p *msgs.Persistent) {
+			Expect(p.Type.(*msgs.Persistent_PEntry).PEntry.SeqNo).To(Equal(index))
+			seqNos = append(seqNos, index)
+		})
+		return seqNos, err
+	}
+
+	// entryEnd returns the offset of the end of the given (1-indexed) entry
+	// within the segment file.
+	entryEnd := func(data []byte, index int) int {
+		offset := 0
+		for i := 0; i < index; i++ {
+			size, n := binary.Uvarint(data[offset:])
+			offset += n + int(size)
+		}
+		return offset
+	}
+
+	corrupt := func(mutate func(data []byte) []byte) {
+		Expect(wal.Close()).To(Succeed())
+		data, err := ioutil.ReadFile(segmentPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(segmentPath, mutate(data), 0600)).To(Succeed())
+	}
+
+	reopen := func() {
+		var err error
+		wal, err = simplewal.Open(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "simplewal-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		segmentPath = filepath.Join(tmpDir, "00000000000000000001")
+
+		reopen()
+		for i := uint64(1); i <= 3; i++ {
+			Expect(wal.Write(i, entry(i))).To(Succeed())
+		}
+		Expect(wal.Sync()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		wal.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("loads the entries written", func() {
+		reopen()
+		Expect(loadAll()).To(Equal([]uint64{1, 2, 3}))
+		Expect(wal.Repairs()).To(BeEmpty())
+	})
+
+	It("truncates a partial entry at the tail on open", func() {
+		corrupt(func(data []byte) []byte {
+			return data[:len(data)-3]
+		})
+
+		reopen()
+		Expect(wal.Repairs()).To(HaveLen(1))
+		Expect(wal.Repairs()[0].FirstIndex).To(Equal(uint64(3)))
+		Expect(loadAll()).To(Equal([]uint64{1, 2}))
+
+		Expect(wal.Write(3, entry(3))).To(Succeed())
+		Expect(loadAll()).To(Equal([]uint64{1, 2, 3}))
+	})
+
+	It("truncates tail entries which fail their checksum", func() {
+		corrupt(func(data []byte) []byte {
+			// A torn write, where the filesystem zero filled the final blocks
+			end := entryEnd(data, 2)
+			for i := end + 2; i < len(data); i++ {
+				data[i] = 0
+			}
+			return append(data, 0, 0, 0)
+		})
+
+		reopen()
+		Expect(loadAll()).To(Equal([]uint64{1, 2}))
+		Expect(wal.Repairs()).To(HaveLen(1))
+		Expect(wal.Repairs()[0].FirstIndex).To(Equal(uint64(3)))
+
+		Expect(wal.Write(3, entry(3))).To(Succeed())
+		Expect(loadAll()).To(Equal([]uint64{1, 2, 3}))
+	})
+
+	It("reports corruption of a middle entry without skipping it", func() {
+		corrupt(func(data []byte) []byte {
+			data[entryEnd(data, 2)-1] ^= 0xff
+			return data
+		})
+
+		reopen()
+		seqNos, err := loadAll()
+		Expect(seqNos).To(BeEmpty())
+		Expect(err).To(HaveOccurred())
+		corruptionErr, ok := err.(*simplewal.CorruptionError)
+		Expect(ok).To(BeTrue())
+		Expect(corruptionErr.Index).To(Equal(uint64(2)))
+		Expect(err.Error()).To(ContainSubstring("WAL corrupt at index 2: entry checksum"))
+		Expect(wal.Repairs()).To(BeEmpty())
+	})
+
+	It("reports corruption of a log none of whose entries are valid", func() {
+		corrupt(func(data []byte) []byte {
+			for i := 2; i < len(data); i++ {
+				data[i] = 0
+			}
+			return data
+		})
+
+		reopen()
+		Expect(wal.IsEmpty()).To(BeFalse())
+		seqNos, err := loadAll()
+		Expect(seqNos).To(BeEmpty())
+		Expect(err).To(HaveOccurred())
+		corruptionErr, ok := err.(*simplewal.CorruptionError)
+		Expect(ok).To(BeTrue())
+		Expect(corruptionErr.Index).To(Equal(uint64(1)))
+		Expect(corruptionErr.Reason).To(HavePrefix("no valid entries in log: "))
+		Expect(wal.Repairs()).To(BeEmpty())
+	})
+
+	It("loads entries written without checksums", func() {
+		Expect(wal.Close()).To(Succeed())
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+
+		legacy, err := tidwall.Open(tmpDir, nil)
+		Expect(err).NotTo(HaveOccurred())
+		for i := uint64(1); i <= 2; i++ {
+			data, err := proto.Marshal(entry(i))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(legacy.Write(i, data)).To(Succeed())
+		}
+		Expect(legacy.Close()).To(Succeed())
+
+		reopen()
+		Expect(wal.Write(3, entry(3))).To(Succeed())
+		Expect(loadAll()).To(Equal([]uint64{1, 2, 3}))
+		Expect(wal.Repairs()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("WAL sync modes", func() {
+	var (
+		tmpDir string
+		wal    *simplewal.WAL
+	)
+
+	open := func(opts *simplewal.Options) {
+		var err error
+		wal, err = simplewal.OpenWithOptions(tmpDir, opts)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	// writeAndSyncConcurrently writes a batch of entries from each of count
+	// go routines, and once every batch is written, syncs from each of them.
+	writeAndSyncConcurrently := func(count int) {
+		var writeMutex sync.Mutex
+		var nextIndex uint64 = 1
+		var written, synced sync.WaitGroup
+		written.Add(count)
+		synced.Add(count)
+		for i := 0; i < count; i++ {
+			go func() {
+				defer GinkgoRecover()
+				defer synced.Done()
+
+				writeMutex.Lock()
+				for j := 0; j < 2; j++ {
+					Expect(wal.Write(nextIndex, &msgs.Persistent{
+						Type: &msgs.Persistent_ECEntry{
+							ECEntry: &msgs.ECEntry{
+								EpochNumber: nextIndex,
+							},
+						},
+					})).To(Succeed())
+					nextIndex++
+				}
+				writeMutex.Unlock()
+
+				written.Done()
+				written.Wait()
+				Expect(wal.Sync()).To(Succeed())
+			}()
+		}
+		synced.Wait()
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "simplewal-test-*")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		wal.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("fsyncs on every sync in per batch mode", func() {
+		open(&simplewal.Options{})
+		writeAndSyncConcurrently(4)
+		Expect(wal.Sync()).To(Succeed())
+
+		stats := wal.Stats()
+		Expect(stats.SyncCalls).To(Equal(uint64(5)))
+		Expect(stats.Sync.Count).To(Equal(uint64(5)))
+		Expect(stats.Write.Count).To(Equal(uint64(8)))
+		Expect(stats.Write.Counts).To(HaveLen(len(simplewal.LatencyBuckets) + 1))
+		total := uint64(0)
+		for _, count := range stats.Write.Counts {
+			total += count
+		}
+		Expect(total).To(Equal(uint64(8)))
+	})
+
+	It("shares one fsync between the batches of concurrent syncs in group commit mode", func() {
+		open(&simplewal.Options{
+			SyncMode:         simplewal.GroupCommit,
+			GroupCommitDelay: 10 * time.Millisecond,
+		})
+		writeAndSyncConcurrently(4)
+
+		stats := wal.Stats()
+		Expect(stats.SyncCalls).To(Equal(uint64(4)))
+		Expect(stats.Sync.Count).To(Equal(uint64(1)))
+		Expect(stats.Write.Count).To(Equal(uint64(8)))
+
+		By("skipping the fsync when nothing has been written")
+		Expect(wal.Sync()).To(Succeed())
+		Expect(wal.Stats().Sync.Count).To(Equal(uint64(1)))
+
+		By("fsyncing again once more is written")
+		Expect(wal.Write(9, &msgs.Persistent{
+			Type: &msgs.Persistent_ECEntry{
+				ECEntry: &msgs.ECEntry{
+					EpochNumber: 9,
+				},
+			},
+		})).To(Succeed())
+		Expect(wal.Sync()).To(Succeed())
+		Expect(wal.Stats().Sync.Count).To(Equal(uint64(2)))
+	})
+})
+
+var _ = Describe("LatencyHistogram", func() {
+	It("computes the mean", func() {
+		Expect(simplewal.LatencyHistogram{}.Mean()).To(Equal(time.Duration(0)))
+		Expect(simplewal.LatencyHistogram{Count: 4, Sum: time.Second}.Mean()).To(Equal(250 * time.Millisecond))
+	})
+})
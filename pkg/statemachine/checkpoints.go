@@ -123,11 +123,19 @@ func (ct *checkpointTracker) filter(_ nodeID, msg *msgs.Msg) applyable {
 }
 
 func (ct *checkpointTracker) step(source nodeID, msg *msgs.Msg) {
+	buffer, ok := ct.msgBuffers[source]
+	if !ok {
+		// The source is a member of the active configuration, but not
+		// of the configuration our checkpoints are tracked under, so
+		// it may not vote on their stability until we reinitialize.
+		return
+	}
+
 	switch ct.filter(source, msg) {
 	case past:
 		return
 	case future:
-		ct.msgBuffers[source].store(msg)
+		buffer.store(msg)
 		fallthrough
 	case current:
 		ct.applyMsg(source, msg)
@@ -240,6 +248,22 @@ func (ct *checkpointTracker) applyCheckpointMsg(source nodeID, seqNo uint64, val
 	}
 }
 
+// highestCorrect returns the highest checkpoint for which at least one
+// correct node vouches for the value, or zero if there is none.
+func (ct *checkpointTracker) highestCorrect() (uint64, []byte) {
+	var seqNo uint64
+	var value []byte
+	for _, cp := range ct.checkpointMap {
+		if cp.committedValue == nil || cp.seqNo <= seqNo {
+			continue
+		}
+
+		seqNo, value = cp.seqNo, cp.committedValue
+	}
+
+	return seqNo, value
+}
+
 func (ct *checkpointTracker) status() []*status.Checkpoint {
 	result := make([]*status.Checkpoint, len(ct.checkpointMap))
 	i := 0
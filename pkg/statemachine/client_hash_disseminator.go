@@ -129,14 +129,21 @@ type clientHashDisseminator struct {
 	msgBuffers       map[nodeID]*msgBuffer
 	clients          map[uint64]*client
 	clientTracker    *clientTracker
+
+	// removedClients are the IDs of clients which have been removed from
+	// the network, so that messages referencing them may be discarded rather
+	// than buffered.  It does not survive a restart, in which case, such
+	// messages are buffered until evicted.
+	removedClients map[uint64]struct{}
 }
 
 func newClientHashDisseminator(nodeBuffers *nodeBuffers, myConfig *state.EventInitialParameters, logger Logger, clientTracker *clientTracker) *clientHashDisseminator {
 	return &clientHashDisseminator{
-		logger:        logger,
-		myConfig:      myConfig,
-		nodeBuffers:   nodeBuffers,
-		clientTracker: clientTracker,
+		logger:         logger,
+		myConfig:       myConfig,
+		nodeBuffers:    nodeBuffers,
+		clientTracker:  clientTracker,
+		removedClients: map[uint64]struct{}{},
 	}
 }
 
@@ -152,6 +159,8 @@ func (ct *clientHashDisseminator) reinitialize(seqNo uint64, networkState *msgs.
 	ct.allocatedThrough = seqNo
 	ct.networkConfig = networkState.Config
 
+	ct.removeClients(networkState.Clients)
+
 	oldClients := ct.clients
 	ct.clients = map[uint64]*client{}
 	ct.clientStates = networkState.Clients
@@ -162,7 +171,6 @@ func (ct *clientHashDisseminator) reinitialize(seqNo uint64, networkState *msgs.
 		}
 
 		ct.clients[clientState.Id] = client
-		// TODO, this assumes no client deletion, need to handle that
 		actions.concat(client.reinitialize(seqNo, networkState.Config, clientState, reconfiguring))
 	}
 
@@ -195,6 +203,9 @@ func (ct *clientHashDisseminator) filter(_ nodeID, msg *msgs.Msg) applyable {
 		ack := innerMsg.RequestAck
 		client, ok := ct.client(ack.ClientId)
 		if !ok {
+			if _, removed := ct.removedClients[ack.ClientId]; removed {
+				return invalid
+			}
 			return future
 		}
 		switch {
@@ -214,7 +225,7 @@ func (ct *clientHashDisseminator) filter(_ nodeID, msg *msgs.Msg) applyable {
 
 func (ct *clientHashDisseminator) step(source nodeID, msg *msgs.Msg) *ActionList {
 	switch ct.filter(source, msg) {
-	case past:
+	case past, invalid:
 		// discard
 		return &ActionList{}
 	case future:
@@ -256,15 +267,31 @@ func (ct *clientHashDisseminator) applyNewRequest(ack *msgs.RequestAck) *ActionL
 
 // allocate should be invoked after the checkpoint is computed and advances the high watermark.
 func (ct *clientHashDisseminator) allocate(seqNo uint64, networkState *msgs.NetworkState) *ActionList {
-	assertEqual(seqNo, uint64(networkState.Config.CheckpointInterval)+ct.allocatedThrough, "unexpected skip in allocate, expected next allocation at next checkpoint")
+	// Note, the checkpoint interval is that of the configuration we are operating
+	// under, the network state may reflect a new configuration.
+	assertEqual(seqNo, uint64(ct.networkConfig.CheckpointInterval)+ct.allocatedThrough, "unexpected skip in allocate, expected next allocation at next checkpoint")
 
 	actions := &ActionList{}
 
 	ct.allocatedThrough = seqNo
 	reconfiguring := len(networkState.PendingReconfigurations) > 0
 
-	for _, client := range networkState.Clients {
-		actions.concat(ct.clients[client.Id].allocate(seqNo, client, reconfiguring))
+	ct.removeClients(networkState.Clients)
+	ct.clientStates = networkState.Clients
+
+	for _, clientState := range networkState.Clients {
+		client, ok := ct.clients[clientState.Id]
+		if !ok {
+			// The client was added by a reconfiguration
+			ct.logger.Log(LevelInfo, "adding client", "client_id", clientState.Id)
+			delete(ct.removedClients, clientState.Id)
+			client = newClient(ct.myConfig, ct.logger, ct.clientTracker)
+			ct.clients[clientState.Id] = client
+			actions.concat(client.reinitialize(seqNo, networkState.Config, clientState, reconfiguring))
+			continue
+		}
+
+		actions.concat(client.allocate(seqNo, clientState, reconfiguring))
 	}
 
 	for _, id := range ct.networkConfig.Nodes {
@@ -275,6 +302,25 @@ func (ct *clientHashDisseminator) allocate(seqNo uint64, networkState *msgs.Netw
 	return actions
 }
 
+// removeClients discards the state of any client which is not in the
+// given set of client states, as it has been removed from the network.
+func (ct *clientHashDisseminator) removeClients(clientStates []*msgs.NetworkState_Client) {
+	remaining := map[uint64]struct{}{}
+	for _, clientState := range clientStates {
+		remaining[clientState.Id] = struct{}{}
+	}
+
+	for _, clientState := range ct.clientStates {
+		if _, ok := remaining[clientState.Id]; ok {
+			continue
+		}
+
+		ct.logger.Log(LevelInfo, "removing client", "client_id", clientState.Id)
+		delete(ct.clients, clientState.Id)
+		ct.removedClients[clientState.Id] = struct{}{}
+	}
+}
+
 func (ct *clientHashDisseminator) replyFetchRequest(source nodeID, clientID, reqNo uint64, digest []byte) *ActionList {
 	c, ok := ct.client(clientID)
 	if !ok {
@@ -437,6 +483,10 @@ func (crn *clientReqNo) applyNewRequest(ack *msgs.RequestAck) *ActionList {
 	clientReq := crn.clientReq(ack)
 	clientReq.stored = true
 
+	// The request may be known through the ack of another replica, but only
+	// our own ack is known to carry the correct size of the request.
+	clientReq.ack = ack
+
 	crn.myRequests[string(ack.Digest)] = clientReq
 
 	actions := &ActionList{}
@@ -636,10 +686,11 @@ type clientRequest struct {
 	myConfig      *state.EventInitialParameters
 	ack           *msgs.RequestAck
 	agreements    map[nodeID]struct{}
-	stored        bool // set when the request is persisted locally
-	fetching      bool // set when we have sent a request for this request
-	ticksFetching uint // incremented by one each tick while fetching is true
-	ticksCorrect  uint // incremented by one each tick while not stored
+	stored        bool   // set when the request is persisted locally
+	fetching      bool   // set when we have sent a request for this request
+	ticksFetching uint   // incremented by one each tick while fetching is true
+	ticksCorrect  uint   // incremented by one each tick while not stored
+	readyTick     uint64 // the proposer tick at which the request became ready to propose
 }
 
 func (cr *clientRequest) fetch() *ActionList {
@@ -712,7 +763,7 @@ func (c *client) reinitialize(seqNo uint64, networkConfig *msgs.NetworkState_Con
 	for reqNo := clientState.LowWatermark; reqNo <= c.highWatermark; reqNo++ {
 		var crn *clientReqNo
 
-		committed := isCommitted(reqNo, clientState)
+		committed := IsCommitted(reqNo, clientState)
 
 		if oldReqNoEl, ok := oldReqNoMap[reqNo]; ok {
 			crn = oldReqNoEl.Value.(*clientReqNo)
@@ -746,7 +797,10 @@ func (c *client) allocate(seqNo uint64, state *msgs.NetworkState_Client, reconfi
 	actions := &ActionList{}
 
 	intermediateHighWatermark := state.LowWatermark + uint64(state.Width) - uint64(state.WidthConsumedLastCheckpoint)
-	assertEqualf(intermediateHighWatermark, c.highWatermark, "new intermediate high watermark should always be the old high watemark, in the allocation path", state.Id)
+	// Ordinarily, the new intermediate high watermark is the old high watermark,
+	// but if the previous checkpoint had pending reconfigurations, we allocated
+	// only through the intermediate high watermark of that checkpoint.
+	assertGreaterThanOrEqualf(intermediateHighWatermark, c.highWatermark, "new intermediate high watermark for client %d should never be below the old high watermark, in the allocation path", state.Id)
 	var newHighWatermark uint64
 	if !reconfiguring {
 		newHighWatermark = state.LowWatermark + uint64(state.Width)
@@ -774,7 +828,7 @@ func (c *client) allocate(seqNo uint64, state *msgs.NetworkState_Client, reconfi
 	}
 
 	for reqNo := state.LowWatermark; reqNo <= c.highWatermark; reqNo++ {
-		if isCommitted(reqNo, state) {
+		if IsCommitted(reqNo, state) {
 			crn := c.reqNoMap[reqNo].Value.(*clientReqNo)
 			crn.committed = true
 		}
@@ -782,10 +836,14 @@ func (c *client) allocate(seqNo uint64, state *msgs.NetworkState_Client, reconfi
 
 	c.clientState = state
 
-	// per the assertions above, the intermediateHighWatermark is always the
-	// old high watermark, so allocate starting after that one.
-	validAfterSeqNo := seqNo + uint64(c.networkConfig.CheckpointInterval)
-	for reqNo := intermediateHighWatermark + 1; reqNo <= newHighWatermark; reqNo++ {
+	// Any request numbers through the intermediate high watermark which
+	// were not allocated (because of a reconfiguration) are valid now,
+	// the remainder only after the next checkpoint.
+	for reqNo := c.highWatermark + 1; reqNo <= newHighWatermark; reqNo++ {
+		validAfterSeqNo := seqNo
+		if reqNo > intermediateHighWatermark {
+			validAfterSeqNo = seqNo + uint64(c.networkConfig.CheckpointInterval)
+		}
 		actions.AllocateRequest(state.Id, reqNo)
 		el := c.reqNoList.PushBack(newClientReqNo(c.myConfig, state.Id, reqNo, c.networkConfig, validAfterSeqNo))
 		c.reqNoMap[reqNo] = el
@@ -813,8 +871,17 @@ func (c *client) ack(source nodeID, ack *msgs.RequestAck) (*ActionList, *clientR
 	if newlyCorrect {
 		crn.weakRequests[string(ack.Digest)] = cr
 
-		if !cr.stored {
+		switch {
+		case cr.stored:
 			// If we already have the req stored, we know it's correct
+		case len(ack.Digest) == 0:
+			// The null request has no data to fetch, so once it is
+			// known to be correct, we may persist and ack it ourselves,
+			// but only if we have not already acked another request.
+			if !crn.committed && len(crn.myRequests) == 0 {
+				actions.concat(crn.applyNewRequest(ack))
+			}
+		default:
 			actions.CorrectRequest(ack)
 		}
 	}
@@ -883,15 +950,15 @@ func (c *client) tick() *ActionList {
 func (c *client) status() *status.ClientTracker {
 	allocated := make([]uint64, c.reqNoList.Len())
 	i := 0
-	lastNonZero := 0
+	nonZeroLen := 0
 	for el := c.reqNoList.Front(); el != nil; el = el.Next() {
 		crn := el.Value.(*clientReqNo)
 		if crn.committed {
-			allocated[i] = 2 // TODO, actually report the seqno it committed to
-			lastNonZero = i
+			allocated[i] = status.ClientReqCommitted // TODO, actually report the seqno it committed to
+			nonZeroLen = i + 1
 		} else if len(crn.requests) > 0 {
-			allocated[i] = 1
-			lastNonZero = i
+			allocated[i] = status.ClientReqAcked
+			nonZeroLen = i + 1
 		}
 		i++
 	}
@@ -900,6 +967,6 @@ func (c *client) status() *status.ClientTracker {
 		ClientID:      c.clientState.Id,
 		LowWatermark:  c.clientState.LowWatermark,
 		HighWatermark: c.highWatermark,
-		Allocated:     allocated[:lastNonZero],
+		Allocated:     allocated[:nonZeroLen],
 	}
 }
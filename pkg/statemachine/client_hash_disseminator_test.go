@@ -0,0 +1,129 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statemachine
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/pb/state"
+)
+
+var _ = Describe("client", func() {
+	var (
+		c             *client
+		networkConfig *msgs.NetworkState_Config
+		nullAck       *msgs.RequestAck
+	)
+
+	BeforeEach(func() {
+		myConfig := &state.EventInitialParameters{
+			Id: 1,
+		}
+
+		networkConfig = &msgs.NetworkState_Config{
+			Nodes:              []uint64{0, 1, 2, 3},
+			F:                  1,
+			CheckpointInterval: 5,
+		}
+
+		clientState := &msgs.NetworkState_Client{
+			Id:    7,
+			Width: 4,
+		}
+
+		tracker := newClientTracker(myConfig, ConsoleErrorLogger)
+		tracker.reinitialize(&msgs.NetworkState{
+			Config:  networkConfig,
+			Clients: []*msgs.NetworkState_Client{clientState},
+		})
+
+		c = newClient(myConfig, ConsoleErrorLogger, tracker)
+		c.reinitialize(0, networkConfig, clientState, false)
+
+		nullAck = &msgs.RequestAck{
+			ClientId: 7,
+			ReqNo:    0,
+		}
+	})
+
+	It("adopts and acks a null request once it is known correct", func() {
+		actions, _ := c.ack(0, nullAck)
+		Expect(actions.Len()).To(Equal(0))
+
+		actions, _ = c.ack(2, nullAck)
+		Expect(actions).To(Equal((&ActionList{}).Send(
+			networkConfig.Nodes,
+			&msgs.Msg{
+				Type: &msgs.Msg_RequestAck{
+					RequestAck: nullAck,
+				},
+			},
+		)))
+		Expect(c.reqNo(0).myRequests).To(HaveKey(""))
+
+		By("becoming ready once our own ack forms a quorum")
+		c.ack(1, nullAck)
+		Expect(c.reqNo(0).strongRequests).To(HaveKey(""))
+		Expect(c.nextReadyMark).To(Equal(uint64(1)))
+	})
+
+	It("does not ack a correct null request once it has acked another request", func() {
+		ack := &msgs.RequestAck{
+			ClientId: 7,
+			ReqNo:    0,
+			Digest:   []byte("digest"),
+		}
+
+		c.reqNo(0).applyNewRequest(ack)
+		Expect(c.reqNo(0).myRequests).To(HaveLen(1))
+
+		c.ack(0, nullAck)
+		actions, _ := c.ack(2, nullAck)
+		Expect(actions.Len()).To(Equal(0))
+		Expect(c.reqNo(0).myRequests).NotTo(HaveKey(""))
+		Expect(c.reqNo(0).weakRequests).To(HaveKey(""))
+	})
+
+	It("fetches rather than adopts other correct requests", func() {
+		ack := &msgs.RequestAck{
+			ClientId: 7,
+			ReqNo:    0,
+			Digest:   []byte("digest"),
+		}
+
+		c.ack(0, ack)
+		actions, _ := c.ack(2, ack)
+		Expect(actions).To(Equal((&ActionList{}).CorrectRequest(ack)))
+		Expect(c.reqNo(0).myRequests).To(BeEmpty())
+	})
+
+	It("uses the size of its own ack rather than that of other replicas", func() {
+		remoteAck := &msgs.RequestAck{
+			ClientId: 7,
+			ReqNo:    0,
+			Digest:   []byte("digest"),
+			Size:     1 << 20,
+		}
+
+		localAck := &msgs.RequestAck{
+			ClientId: 7,
+			ReqNo:    0,
+			Digest:   []byte("digest"),
+			Size:     10,
+		}
+
+		c.ack(0, remoteAck)
+		c.reqNo(0).applyNewRequest(localAck)
+		Expect(c.reqNo(0).requests["digest"].ack.Size).To(Equal(uint32(10)))
+
+		By("ignoring the size of later acks from other replicas")
+		c.ack(2, remoteAck)
+		Expect(c.reqNo(0).requests["digest"].ack.Size).To(Equal(uint32(10)))
+	})
+})
@@ -48,6 +48,9 @@ func (ct *clientTracker) addAvailable(req *msgs.RequestAck) {
 }
 
 func (ct *clientTracker) allocate(seqNo uint64, state *msgs.NetworkState) {
+	ct.networkConfig = state.Config
+	ct.clientStates = state.Clients
+
 	stateMap := map[uint64]*msgs.NetworkState_Client{}
 	for _, client := range state.Clients {
 		stateMap[client.Id] = client
@@ -150,8 +153,11 @@ func (rl *readyList) garbageCollect(clientStates map[uint64]*msgs.NetworkState_C
 	rl.appendList.garbageCollect(func(value interface{}) bool {
 		crn := value.(*clientReqNo)
 		state, ok := clientStates[crn.clientID]
-		assertTrue(ok, "client removal not yet supported") // XXX Fix
-		return isCommitted(crn.reqNo, state)
+		if !ok {
+			// The client has been removed
+			return true
+		}
+		return IsCommitted(crn.reqNo, state)
 	})
 }
 
@@ -185,7 +191,10 @@ func (al *availableList) garbageCollect(states map[uint64]*msgs.NetworkState_Cli
 	al.appendList.garbageCollect(func(value interface{}) bool {
 		ack := value.(*msgs.RequestAck)
 		state, ok := states[ack.ClientId]
-		assertTrue(ok, "any available client req must have client in config")
-		return isCommitted(ack.ReqNo, state)
+		if !ok {
+			// The client has been removed
+			return true
+		}
+		return IsCommitted(ack.ReqNo, state)
 	})
 }
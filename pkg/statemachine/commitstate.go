@@ -11,6 +11,9 @@ import (
 
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 	"github.com/IBM/mirbft/pkg/pb/state"
+	"github.com/IBM/mirbft/pkg/status"
+
+	"google.golang.org/protobuf/proto"
 )
 
 // commitState represents our state, as reflected within our log watermarks.
@@ -35,8 +38,28 @@ type commitState struct {
 	upperHalfCommits  []*msgs.QEntry
 	checkpointPending bool
 	transferring      bool
+
+	// configChangeSeqNo is the sequence number of the checkpoint at which
+	// a committed NewConfig reconfiguration takes effect, or zero if no
+	// such reconfiguration is pending.  It is reset on reinitialization.
+	configChangeSeqNo uint64
+
+	// transferTarget is the checkpoint we are currently attempting
+	// to transfer to.  It is only set while transferring.
+	transferTarget *msgs.TEntry
+
+	// transferFailures counts the consecutive failed attempts to
+	// transfer state, and transferRetryTicks is the number of ticks
+	// remaining before we retry.  transferRetryTicks is zero while
+	// an attempt is outstanding.
+	transferFailures   int
+	transferRetryTicks int
 }
 
+// maxTransferBackoffTicks bounds the exponential backoff between
+// state transfer attempts.
+const maxTransferBackoffTicks = 64
+
 func newCommitState(persisted *persisted, logger Logger) *commitState {
 	cs := &commitState{
 		persisted: persisted,
@@ -68,14 +91,21 @@ func (cs *commitState) reinitialize() *ActionList {
 	}
 
 	ci := uint64(cs.activeState.Config.CheckpointInterval)
-	if len(cs.activeState.PendingReconfigurations) == 0 {
+	switch {
+	case !proto.Equal(cs.activeState.Config, lastCEntry.NetworkState.Config):
+		// The last checkpoint changes the network configuration, but has
+		// not yet been found to be stable, so we may not commit beyond it.
+		cs.stopAtSeqNo = lastCEntry.SeqNo
+	case len(cs.activeState.PendingReconfigurations) == 0:
 		cs.stopAtSeqNo = lastCEntry.SeqNo + 2*ci
-	} else {
+	default:
 		cs.stopAtSeqNo = lastCEntry.SeqNo + ci
 	}
 
 	cs.lastAppliedCommit = lastCEntry.SeqNo
 	cs.highestCommit = lastCEntry.SeqNo
+	cs.configChangeSeqNo = 0
+	cs.trackConfigChange()
 
 	cs.lowerHalfCommits = make([]*msgs.QEntry, ci)
 	cs.upperHalfCommits = make([]*msgs.QEntry, ci)
@@ -85,9 +115,13 @@ func (cs *commitState) reinitialize() *ActionList {
 		cs.committingClients[clientState.Id] = newCommittingClient(lastCEntry.SeqNo, clientState)
 	}
 
+	cs.transferFailures = 0
+	cs.transferRetryTicks = 0
+
 	if lastTEntry == nil || lastCEntry.SeqNo >= lastTEntry.SeqNo {
 		cs.logger.Log(LevelDebug, "reinitialized commit-state", "low_watermark", cs.lowWatermark, "stop_at_seq_no", cs.stopAtSeqNo, "len(pending_reconfigurations)", len(cs.activeState.PendingReconfigurations), "last_checkpoint_seq_no", lastCEntry.SeqNo)
 		cs.transferring = false
+		cs.transferTarget = nil
 		return &ActionList{}
 	}
 
@@ -95,6 +129,7 @@ func (cs *commitState) reinitialize() *ActionList {
 
 	// We crashed during a state transfer
 	cs.transferring = true
+	cs.transferTarget = lastTEntry
 	return (&ActionList{}).StateTransfer(lastTEntry.SeqNo, lastTEntry.Value)
 }
 
@@ -102,10 +137,85 @@ func (cs *commitState) transferTo(seqNo uint64, value []byte) *ActionList {
 	cs.logger.Log(LevelDebug, "initiating state transfer", "target_seq_no", seqNo, "target_value", value)
 	assertEqual(cs.transferring, false, "multiple state transfers are not supported concurrently")
 	cs.transferring = true
-	return cs.persisted.addTEntry(&msgs.TEntry{
+	cs.transferTarget = &msgs.TEntry{
 		SeqNo: seqNo,
 		Value: value,
-	}).StateTransfer(seqNo, value)
+	}
+	return cs.persisted.addTEntry(cs.transferTarget).StateTransfer(seqNo, value)
+}
+
+// transferFailed is invoked when the application reports it could not
+// transfer to the requested checkpoint.  Rather than give up, we wait for
+// an exponentially increasing number of ticks and then try again, possibly
+// against a newer checkpoint (see retryTransfer).
+func (cs *commitState) transferFailed(seqNo uint64, value []byte) {
+	if !cs.transferring || cs.transferRetryTicks > 0 || cs.transferTarget.SeqNo != seqNo || !bytes.Equal(cs.transferTarget.Value, value) {
+		cs.logger.Log(LevelDebug, "ignoring stale state transfer failure", "seq_no", seqNo)
+		return
+	}
+
+	cs.transferFailures++
+	cs.transferRetryTicks = maxTransferBackoffTicks
+	if cs.transferFailures <= 6 {
+		cs.transferRetryTicks = 1 << (cs.transferFailures - 1)
+	}
+
+	cs.logger.Log(LevelWarn, "state transfer failed, will retry", "seq_no", seqNo, "failures", cs.transferFailures, "retry_ticks", cs.transferRetryTicks)
+}
+
+// retryTransfer should be invoked on each tick.  Once the backoff for a failed
+// state transfer has elapsed, the transfer is re-requested so that the
+// application may attempt another source.  If the network has since agreed
+// on a later checkpoint than our current target, we transfer to that
+// checkpoint instead, as the current target may no longer be available.
+func (cs *commitState) retryTransfer(correctSeqNo uint64, correctValue []byte) *ActionList {
+	if !cs.transferring || cs.transferRetryTicks == 0 {
+		return &ActionList{}
+	}
+
+	cs.transferRetryTicks--
+	if cs.transferRetryTicks > 0 {
+		return &ActionList{}
+	}
+
+	if correctSeqNo <= cs.transferTarget.SeqNo {
+		cs.logger.Log(LevelInfo, "retrying state transfer", "target_seq_no", cs.transferTarget.SeqNo, "failures", cs.transferFailures)
+		return (&ActionList{}).StateTransfer(cs.transferTarget.SeqNo, cs.transferTarget.Value)
+	}
+
+	cs.logger.Log(LevelInfo, "retrying state transfer against newer checkpoint", "target_seq_no", correctSeqNo, "previous_target_seq_no", cs.transferTarget.SeqNo, "failures", cs.transferFailures)
+	cs.transferTarget = &msgs.TEntry{
+		SeqNo: correctSeqNo,
+		Value: correctValue,
+	}
+	return cs.persisted.addTEntry(cs.transferTarget).StateTransfer(correctSeqNo, correctValue)
+}
+
+// catchUp initiates a state transfer to the given correct checkpoint if we are
+// not a member of our active network configuration, as is the case for a node
+// which is joining the network.  Such a node cannot take part in the epochs of
+// the network, so it may only make progress by transferring to a checkpoint
+// whose network configuration includes it.
+func (cs *commitState) catchUp(myID nodeID, correctSeqNo uint64, correctValue []byte) *ActionList {
+	if cs.transferring || correctSeqNo <= cs.highestCommit || isMember(myID, cs.activeState.Config) {
+		return &ActionList{}
+	}
+
+	cs.logger.Log(LevelInfo, "not a member of the network configuration, transferring to correct checkpoint", "target_seq_no", correctSeqNo)
+	return cs.transferTo(correctSeqNo, correctValue)
+}
+
+func (cs *commitState) status() *status.StateTransfer {
+	if !cs.transferring {
+		return nil
+	}
+
+	return &status.StateTransfer{
+		SeqNo:      cs.transferTarget.SeqNo,
+		Value:      cs.transferTarget.Value,
+		Failures:   cs.transferFailures,
+		RetryTicks: cs.transferRetryTicks,
+	}
 }
 
 func (cs *commitState) applyCheckpointResult(epochConfig *msgs.EpochConfig, result *state.EventCheckpointResult) *ActionList {
@@ -120,24 +230,40 @@ func (cs *commitState) applyCheckpointResult(epochConfig *msgs.EpochConfig, resu
 		panic("dev sanity test -- this panic is helpful for dev, but needs to be removed as we could get stale checkpoint results")
 	}
 
-	if len(result.NetworkState.PendingReconfigurations) == 0 {
+	configChanged := !proto.Equal(cs.activeState.Config, result.NetworkState.Config)
+	targets := result.NetworkState.Config.Nodes
+
+	switch {
+	case configChanged:
+		// We may not commit beyond this checkpoint until it is stable and
+		// we have reinitialized under the new configuration.  Nodes removed
+		// by the new configuration still need our checkpoint message to
+		// determine that it is stable.
+		cs.logger.Log(LevelInfo, "checkpoint result changes the network configuration, stopping at checkpoint", "stop_at_seq_no", cs.stopAtSeqNo)
+		targets = unionNodes(cs.activeState.Config.Nodes, result.NetworkState.Config.Nodes)
+	case len(result.NetworkState.PendingReconfigurations) == 0:
 		cs.stopAtSeqNo = result.SeqNo + 2*ci
-	} else {
-		cs.logger.Log(LevelDebug, "checkpoint result has pending reconfigurations, not extending stop", "stop_at_seq_no", cs.stopAtSeqNo)
+	default:
+		// The pending reconfigurations take effect at the next checkpoint,
+		// so we may only commit through it, as on reinitialization.
+		cs.stopAtSeqNo = result.SeqNo + ci
+		cs.logger.Log(LevelDebug, "checkpoint result has pending reconfigurations, stopping at next checkpoint", "stop_at_seq_no", cs.stopAtSeqNo)
 	}
 
 	cs.activeState = result.NetworkState
+	cs.reconfigureCommittingClients(result.SeqNo, result.NetworkState.Clients)
 	cs.lowerHalfCommits = cs.upperHalfCommits
 	cs.upperHalfCommits = make([]*msgs.QEntry, ci)
 	cs.lowWatermark = result.SeqNo
 	cs.checkpointPending = false
+	cs.trackConfigChange()
 
 	return cs.persisted.addCEntry(&msgs.CEntry{
 		SeqNo:           result.SeqNo,
 		CheckpointValue: result.Value,
 		NetworkState:    result.NetworkState,
 	}).Send(
-		cs.activeState.Config.Nodes,
+		targets,
 		&msgs.Msg{
 			Type: &msgs.Msg_Checkpoint{
 				Checkpoint: &msgs.Checkpoint{
@@ -149,6 +275,40 @@ func (cs *commitState) applyCheckpointResult(epochConfig *msgs.EpochConfig, resu
 	)
 }
 
+// trackConfigChange records the sequence number of the checkpoint at which
+// the active state's pending reconfigurations change the network config, if
+// they include a NewConfig.  The active state is that of the checkpoint at the
+// low watermark, and its pending reconfigurations are applied at the next.
+// The change remains tracked until we reinitialize under the new config.
+func (cs *commitState) trackConfigChange() {
+	for _, reconfig := range cs.activeState.PendingReconfigurations {
+		if _, ok := reconfig.Type.(*msgs.Reconfiguration_NewConfig); ok {
+			cs.configChangeSeqNo = cs.lowWatermark + uint64(cs.activeState.Config.CheckpointInterval)
+			return
+		}
+	}
+}
+
+// reconfigureCommittingClients begins tracking the commits of any client
+// which was added to the network by a reconfiguration, and discards the
+// committing state of any client which was removed.  Requests for removed
+// clients may not commit after the checkpoint which removed them.
+func (cs *commitState) reconfigureCommittingClients(seqNo uint64, clientStates []*msgs.NetworkState_Client) {
+	remaining := map[uint64]struct{}{}
+	for _, clientState := range clientStates {
+		remaining[clientState.Id] = struct{}{}
+		if _, ok := cs.committingClients[clientState.Id]; !ok {
+			cs.committingClients[clientState.Id] = newCommittingClient(seqNo, clientState)
+		}
+	}
+
+	for clientID := range cs.committingClients {
+		if _, ok := remaining[clientID]; !ok {
+			delete(cs.committingClients, clientID)
+		}
+	}
+}
+
 func (cs *commitState) commit(qEntry *msgs.QEntry) {
 	assertEqual(cs.transferring, false, "we should never commit during state transfer")
 	assertGreaterThanOrEqual(cs.stopAtSeqNo, qEntry.SeqNo, "commit sequence exceeds stop sequence")
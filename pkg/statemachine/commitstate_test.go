@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statemachine
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/pb/state"
+)
+
+var _ = Describe("commitState state transfer", func() {
+	var (
+		cs *commitState
+	)
+
+	ticksUntilRetry := func(seqNo uint64, value []byte) int {
+		for i := 1; i <= maxTransferBackoffTicks; i++ {
+			actions := cs.retryTransfer(seqNo, value)
+			if actions.Len() > 0 {
+				return i
+			}
+		}
+		return -1
+	}
+
+	BeforeEach(func() {
+		p := newPersisted(ConsoleErrorLogger)
+		p.appendInitialLoad(1, &msgs.Persistent{
+			Type: &msgs.Persistent_CEntry{
+				CEntry: &msgs.CEntry{
+					SeqNo:           0,
+					CheckpointValue: []byte("genesis"),
+					NetworkState: &msgs.NetworkState{
+						Config: &msgs.NetworkState_Config{
+							Nodes:              []uint64{0, 1, 2, 3},
+							F:                  1,
+							CheckpointInterval: 5,
+						},
+					},
+				},
+			},
+		})
+
+		cs = newCommitState(p, ConsoleErrorLogger)
+		cs.reinitialize()
+		cs.transferTo(10, []byte("ten"))
+	})
+
+	It("reports the transfer in progress", func() {
+		Expect(cs.status().SeqNo).To(Equal(uint64(10)))
+		Expect(cs.status().Failures).To(Equal(0))
+	})
+
+	It("retries the same target with exponential backoff", func() {
+		cs.transferFailed(10, []byte("ten"))
+		Expect(cs.status().Failures).To(Equal(1))
+		Expect(ticksUntilRetry(0, nil)).To(Equal(1))
+
+		cs.transferFailed(10, []byte("ten"))
+		Expect(ticksUntilRetry(0, nil)).To(Equal(2))
+
+		cs.transferFailed(10, []byte("ten"))
+		Expect(ticksUntilRetry(0, nil)).To(Equal(4))
+		Expect(cs.status().Failures).To(Equal(3))
+	})
+
+	It("ignores failures for other targets", func() {
+		cs.transferFailed(5, []byte("five"))
+		Expect(cs.status().Failures).To(Equal(0))
+		Expect(cs.retryTransfer(0, nil).Len()).To(Equal(0))
+	})
+
+	It("retargets to a newer correct checkpoint", func() {
+		cs.transferFailed(10, []byte("ten"))
+		actions := cs.retryTransfer(15, []byte("fifteen"))
+
+		iter := actions.Iterator()
+		persist := iter.Next().Type.(*state.Action_AppendWriteAhead).AppendWriteAhead
+		Expect(persist.Data.Type.(*msgs.Persistent_TEntry).TEntry.SeqNo).To(Equal(uint64(15)))
+		transfer := iter.Next().Type.(*state.Action_StateTransfer).StateTransfer
+		Expect(transfer.SeqNo).To(Equal(uint64(15)))
+		Expect(transfer.Value).To(Equal([]byte("fifteen")))
+		Expect(cs.status().SeqNo).To(Equal(uint64(15)))
+	})
+})
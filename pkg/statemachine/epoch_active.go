@@ -121,6 +121,25 @@ func newActiveEpoch(epochConfig *msgs.EpochConfig, persisted *persisted, nodeBuf
 	}
 }
 
+// reconfigureClients begins tracking the requests of clients which were
+// added by a reconfiguration, and discards any outstanding or proposable
+// requests for clients which are no longer in the network state, as the
+// client has been removed by a reconfiguration.
+func (e *activeEpoch) reconfigureClients(clientStates []*msgs.NetworkState_Client) {
+	remaining := map[uint64]struct{}{}
+	for _, clientState := range clientStates {
+		remaining[clientState.Id] = struct{}{}
+	}
+
+	removed := func(clientID uint64) bool {
+		_, ok := remaining[clientID]
+		return !ok
+	}
+
+	e.outstandingReqs.reconfigureClients(clientStates, e.networkConfig, removed)
+	e.proposer.removeClients(removed)
+}
+
 func (e *activeEpoch) seqToBucket(seqNo uint64) bucketID {
 	return seqToBucket(seqNo, e.networkConfig)
 }
@@ -436,6 +455,8 @@ func (e *activeEpoch) applyBatchHashResult(seqNo uint64, digest []byte) *ActionL
 }
 
 func (e *activeEpoch) tick() *ActionList {
+	e.proposer.tick()
+
 	if e.lastCommittedAtTick < e.commitState.highestCommit {
 		e.lastCommittedAtTick = e.commitState.highestCommit
 		e.ticksSinceProgress = 0
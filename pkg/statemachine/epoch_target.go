@@ -51,7 +51,9 @@ type epochTarget struct {
 	suspicions      map[nodeID]struct{}
 	myNewEpoch      *msgs.NewEpoch // The NewEpoch msg we computed from the epoch changes we know of
 	myEpochChange   *parsedEpochChange
-	myLeaderChoice  []uint64             // Set along with myEpochChange
+	epochHistory    *EpochHistory // Set along with myEpochChange
+	leaderPolicy    LeaderPolicy
+	gracefulEnd     bool                 // Set if this epoch ended by reaching its planned expiration
 	leaderNewEpoch  *msgs.NewEpoch       // The NewEpoch msg we received directly from the leader
 	networkNewEpoch *msgs.NewEpochConfig // The NewEpoch msg as received via the bracha broadcast
 	isLeader        bool
@@ -95,7 +97,7 @@ func newEpochTarget(
 		strongChanges:          map[nodeID]*parsedEpochChange{},
 		echos:                  map[*msgs.NewEpochConfig]map[nodeID]struct{}{},
 		readies:                map[*msgs.NewEpochConfig]map[nodeID]struct{}{},
-		isLeader:               number%uint64(len(networkConfig.Nodes)) == myConfig.Id,
+		isLeader:               epochPrimary(number, networkConfig.Nodes) == myConfig.Id,
 		prestartBuffers:        prestartBuffers,
 		persisted:              persisted,
 		nodeBuffers:            nodeBuffers,
@@ -283,9 +285,6 @@ func (et *epochTarget) fetchNewEpochState() *ActionList {
 		return actions
 	}
 
-	et.logger.Log(LevelDebug, "epoch transitioning from fetching to echoing", "epoch_no", et.number)
-	et.state = etEchoing
-
 	if newEpochConfig.StartingCheckpoint.SeqNo == et.commitState.stopAtSeqNo && len(newEpochConfig.FinalPreprepares) > 0 {
 		// We know at this point that
 		// newEpochConfig.StartingCheckpoint.SeqNo <= et.commitState.lowWatermark
@@ -293,20 +292,22 @@ func (et *epochTarget) fetchNewEpochState() *ActionList {
 		// Further, since this epoch change is correct, we know that some correct replica
 		// prepared some sequence beyond the starting checkpoint.  Since a correct replica
 		// will wait for a strong checkpoint quorum before preparing beyond a reconfiguration
-		// we therefore know that this checkpoint is in fact stable, and we must
-		// reinitialize under the new network configuration before processing further.
-
-		// XXX the problem becomes though, that in order to reinitialize, we need to
-		// append the FEntry, but, then we truncate the log, and if we crash, we come
-		// back up unaware of our previous epoch change message.  Fine, we know
-		// the checkpoint is strong, but, without epoch message reliable
-		// (re)broadcast, the new view timer might never start at other nodes
-		// and we could get stuck in this epoch forever.
-
-		panic("deal with this")
+		// we therefore know that this checkpoint is in fact stable, and this epoch belongs
+		// to the new network configuration.  Every node sends its checkpoint message for
+		// a reconfiguration to the nodes of both configurations, so we will observe the
+		// checkpoint becoming stable, and reinitialize under the new configuration.  Until
+		// then, we simply do not process this epoch further.
+		et.logger.Log(LevelInfo, "new epoch begins after reconfiguration, waiting for checkpoint to become stable", "epoch_no", et.number, "seq_no", newEpochConfig.StartingCheckpoint.SeqNo)
+		return actions
 	}
 
-	// XXX what if the final preprepares span both an old and new config?
+	et.logger.Log(LevelDebug, "epoch transitioning from fetching to echoing", "epoch_no", et.number)
+	et.state = etEchoing
+
+	// Note, the final preprepares cannot span both an old and a new network configuration.
+	// The stop sequence is never extended past a checkpoint which changes the configuration,
+	// so no correct replica prepares beyond it until it is stable, at which point the replica
+	// reinitializes, and participates only in epochs of the new configuration.
 
 	actions.concat(et.persisted.addNEntry(&msgs.NEntry{
 		SeqNo:       newEpochConfig.StartingCheckpoint.SeqNo + 1,
@@ -511,7 +512,7 @@ func (et *epochTarget) checkEpochQuorum() *ActionList {
 		return &ActionList{}
 	}
 
-	et.myNewEpoch = et.constructNewEpoch(et.myLeaderChoice, et.networkConfig)
+	et.myNewEpoch = et.constructNewEpoch(et.leaderChoice(), et.networkConfig)
 	if et.myNewEpoch == nil {
 
 		return &ActionList{}
@@ -534,6 +535,27 @@ func (et *epochTarget) checkEpochQuorum() *ActionList {
 	return &ActionList{}
 }
 
+// leaderChoice consults the leader policy for the leaders this node
+// would propose for this epoch, given the nodes known to be responsive.
+// If the policy selects an invalid leader set, the default policy is used.
+func (et *epochTarget) leaderChoice() []uint64 {
+	history := *et.epochHistory
+	history.Responsive = nil
+	for _, id := range et.networkConfig.Nodes {
+		if _, ok := et.strongChanges[nodeID(id)]; ok {
+			history.Responsive = append(history.Responsive, id)
+		}
+	}
+
+	leaders := et.leaderPolicy.Leaders(&history)
+	if err := ValidateLeaders(&history, leaders); err != nil {
+		et.logger.Log(LevelWarn, "leader policy selected invalid leaders, using default policy", "epoch_no", et.number, "leaders", leaders, "error", err)
+		return DefaultLeaderPolicy{}.Leaders(&history)
+	}
+
+	return leaders
+}
+
 func (et *epochTarget) applyNewEpochMsg(msg *msgs.NewEpoch) *ActionList {
 	et.leaderNewEpoch = msg
 	return et.advanceState()
@@ -754,6 +776,7 @@ func (et *epochTarget) moveLowWatermark(seqNo uint64) *ActionList {
 	if done {
 		et.logger.Log(LevelDebug, "epoch gracefully transitioning from in progress to done", "epoch_no", et.number)
 		et.state = etDone
+		et.gracefulEnd = true
 	}
 
 	return actions
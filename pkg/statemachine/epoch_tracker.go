@@ -13,6 +13,8 @@ import (
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 	"github.com/IBM/mirbft/pkg/pb/state"
 	"github.com/IBM/mirbft/pkg/status"
+
+	"google.golang.org/protobuf/proto"
 )
 
 type epochTracker struct {
@@ -29,6 +31,8 @@ type epochTracker struct {
 	futureMsgs             map[nodeID]*msgBuffer
 	targets                map[uint64]*epochTarget
 	needsStateTransfer     bool
+	leaderPolicy           LeaderPolicy
+	lastActiveEpoch        *msgs.EpochConfig
 
 	maxEpochs              map[nodeID]uint64
 	maxCorrectEpoch        uint64
@@ -45,8 +49,10 @@ func newEpochTracker(
 	batchTracker *batchTracker,
 	clientTracker *clientTracker,
 	clientHashDisseminator *clientHashDisseminator,
+	leaderPolicy LeaderPolicy,
 ) *epochTracker {
 	return &epochTracker{
+		leaderPolicy:           leaderPolicy,
 		persisted:              persisted,
 		nodeBuffers:            nodeBuffers,
 		commitState:            commitState,
@@ -61,9 +67,16 @@ func newEpochTracker(
 }
 
 func (et *epochTracker) reinitialize() *ActionList {
+	if et.networkConfig != nil && !proto.Equal(et.networkConfig, et.commitState.activeState.Config) {
+		// We are reinitializing under a new network configuration, any
+		// epoch target we have is for the old configuration, and may not
+		// be resumed.
+		et.currentEpoch = nil
+	}
 	et.networkConfig = et.commitState.activeState.Config
 
 	newFutureMsgs := map[nodeID]*msgBuffer{}
+	newMaxEpochs := map[nodeID]uint64{}
 	for _, id := range et.networkConfig.Nodes {
 		futureMsgs, ok := et.futureMsgs[nodeID(id)]
 		if !ok {
@@ -73,14 +86,19 @@ func (et *epochTracker) reinitialize() *ActionList {
 			)
 		}
 		newFutureMsgs[nodeID(id)] = futureMsgs
+		if maxEpoch, ok := et.maxEpochs[nodeID(id)]; ok {
+			newMaxEpochs[nodeID(id)] = maxEpoch
+		}
 	}
 	et.futureMsgs = newFutureMsgs
+	et.maxEpochs = newMaxEpochs
 
 	actions := &ActionList{}
 	var lastNEntry *msgs.NEntry
 	var lastECEntry *msgs.ECEntry
 	var lastFEntry *msgs.FEntry
 	var highestPreprepared uint64
+	var suspects []*msgs.Suspect
 
 	et.persisted.iterate(logIterator{
 		onNEntry: func(nEntry *msgs.NEntry) {
@@ -104,9 +122,9 @@ func (et *epochTracker) reinitialize() *ActionList {
 				highestPreprepared = cEntry.SeqNo
 			}
 		},
-
-		// TODO, implement
-		onSuspect: func(*msgs.Suspect) {},
+		onSuspect: func(suspect *msgs.Suspect) {
+			suspects = append(suspects, suspect)
+		},
 	})
 
 	var lastEpochConfig *msgs.EpochConfig
@@ -126,6 +144,8 @@ func (et *epochTracker) reinitialize() *ActionList {
 		panic("no active epoch and no last epoch in log")
 	}
 
+	et.lastActiveEpoch = lastEpochConfig
+
 	switch {
 	case lastNEntry != nil && (lastECEntry == nil || lastECEntry.EpochNumber <= lastNEntry.EpochConfig.Number):
 		et.logger.Log(LevelDebug, "reinitializing during a currently active epoch")
@@ -201,11 +221,24 @@ func (et *epochTracker) reinitialize() *ActionList {
 		)
 
 		et.currentEpoch.myEpochChange = parsedEpochChange
+		et.currentEpoch.leaderPolicy = et.leaderPolicy
+
+		// We only know of our own suspicions across a restart
+		var mySuspects []uint64
+		for _, suspect := range suspects {
+			if suspect.Epoch == lastEpochConfig.Number {
+				mySuspects = []uint64{et.myConfig.Id}
+			}
+		}
 
-		// XXX this leader selection is wrong, but using while we modify the startup.
-		// instead base it on the lastEpochConfig and whether that epoch ended gracefully.
-		_, _ = lastEpochConfig, graceful
-		et.currentEpoch.myLeaderChoice = et.networkConfig.Nodes
+		et.currentEpoch.epochHistory = &EpochHistory{
+			NewEpoch:    epochChange.NewEpoch,
+			Nodes:       et.networkConfig.Nodes,
+			LastEpoch:   lastEpochConfig.Number,
+			LastLeaders: lastEpochConfig.Leaders,
+			Graceful:    graceful,
+			Suspects:    mySuspects,
+		}
 	default:
 		// There's no active epoch, it did not end gracefully, or ungracefully
 		panic("no recorded active epoch, ended epoch, or epoch change in log")
@@ -240,6 +273,18 @@ func (et *epochTracker) advanceState() *ActionList {
 	myEpochChange, err := newParsedEpochChange(epochChange)
 	assertEqualf(err, nil, "could not parse epoch change we generated: %s", err)
 
+	lastEpoch := et.currentEpoch
+	if lastEpoch.activeEpoch != nil {
+		et.lastActiveEpoch = lastEpoch.activeEpoch.epochConfig
+	}
+
+	var suspects []uint64
+	for _, id := range et.networkConfig.Nodes {
+		if _, ok := lastEpoch.suspicions[nodeID(id)]; ok {
+			suspects = append(suspects, id)
+		}
+	}
+
 	et.currentEpoch = newEpochTarget(
 		newEpochNumber,
 		et.persisted,
@@ -253,7 +298,15 @@ func (et *epochTracker) advanceState() *ActionList {
 		et.logger,
 	)
 	et.currentEpoch.myEpochChange = myEpochChange
-	et.currentEpoch.myLeaderChoice = []uint64{et.myConfig.Id} // XXX, wrong
+	et.currentEpoch.leaderPolicy = et.leaderPolicy
+	et.currentEpoch.epochHistory = &EpochHistory{
+		NewEpoch:    newEpochNumber,
+		Nodes:       et.networkConfig.Nodes,
+		LastEpoch:   lastEpoch.number,
+		LastLeaders: et.lastActiveEpoch.Leaders,
+		Graceful:    lastEpoch.gracefulEnd,
+		Suspects:    suspects,
+	}
 
 	actions := et.persisted.addECEntry(&msgs.ECEntry{
 		EpochNumber: newEpochNumber,
@@ -352,7 +405,7 @@ func (et *epochTracker) applyMsg(source nodeID, msg *msgs.Msg) *ActionList {
 	case *msgs.Msg_EpochChangeAck:
 		return target.applyEpochChangeAckMsg(source, nodeID(innerMsg.EpochChangeAck.Originator), innerMsg.EpochChangeAck.EpochChange)
 	case *msgs.Msg_NewEpoch:
-		if innerMsg.NewEpoch.NewConfig.Config.Number%uint64(len(et.networkConfig.Nodes)) != uint64(source) {
+		if epochPrimary(innerMsg.NewEpoch.NewConfig.Config.Number, et.networkConfig.Nodes) != uint64(source) {
 			// TODO, log oddity
 			return &ActionList{}
 		}
@@ -16,7 +16,10 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/pb/state"
 	. "github.com/IBM/mirbft/pkg/testengine"
+	"google.golang.org/protobuf/proto"
 )
 
 var _ = Describe("Mirbft", func() {
@@ -76,6 +79,287 @@ var _ = Describe("Mirbft", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	When("a client is removed by reconfiguration", func() {
+		BeforeEach(func() {
+			recorder.ReconfigPoints = []*ReconfigPoint{
+				{
+					ClientID: 0,
+					ReqNo:    10,
+					Reconfiguration: &msgs.Reconfiguration{
+						Type: &msgs.Reconfiguration_RemoveClient{
+							RemoveClient: 3,
+						},
+					},
+				},
+			}
+		})
+
+		It("delivers all requests of the remaining clients", func() {
+			_, err := recording.DrainClients(50000)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range recording.Nodes {
+				for _, client := range node.State.LastCheckpoint().NetworkState.Clients {
+					Expect(client.Id).NotTo(Equal(uint64(3)))
+				}
+			}
+		})
+	})
+
+	When("a client is added by reconfiguration", func() {
+		BeforeEach(func() {
+			recorder.ClientConfigs = append(recorder.ClientConfigs, &ClientConfig{
+				ID:          4,
+				MaxInFlight: 10,
+				Total:       100,
+			})
+			recorder.ReconfigPoints = []*ReconfigPoint{
+				{
+					ClientID: 0,
+					ReqNo:    10,
+					Reconfiguration: &msgs.Reconfiguration{
+						Type: &msgs.Reconfiguration_NewClient_{
+							NewClient: &msgs.Reconfiguration_NewClient{
+								Id:    4,
+								Width: 100,
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("delivers all requests, including those of the new client", func() {
+			_, err := recording.DrainClients(50000)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range recording.Nodes {
+				clients := node.State.LastCheckpoint().NetworkState.Clients
+				Expect(clients).To(HaveLen(5))
+				Expect(clients[4].Id).To(Equal(uint64(4)))
+				Expect(clients[4].LowWatermark).To(Equal(uint64(100)))
+			}
+		})
+	})
+
+	When("clients are reconfigured at consecutive checkpoints", func() {
+		BeforeEach(func() {
+			recorder.ClientConfigs = append(recorder.ClientConfigs, &ClientConfig{
+				ID:          4,
+				MaxInFlight: 10,
+				Total:       100,
+			})
+			recorder.ReconfigPoints = []*ReconfigPoint{
+				{
+					ClientID: 0,
+					ReqNo:    10,
+					Reconfiguration: &msgs.Reconfiguration{
+						Type: &msgs.Reconfiguration_NewClient_{
+							NewClient: &msgs.Reconfiguration_NewClient{
+								Id:    4,
+								Width: 100,
+							},
+						},
+					},
+				},
+				{
+					ClientID: 0,
+					ReqNo:    20,
+					Reconfiguration: &msgs.Reconfiguration{
+						Type: &msgs.Reconfiguration_RemoveClient{
+							RemoveClient: 3,
+						},
+					},
+				},
+			}
+		})
+
+		It("delivers all requests without ending the epoch at either checkpoint", func() {
+			_, err := recording.DrainClients(50000)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range recording.Nodes {
+				clients := node.State.LastCheckpoint().NetworkState.Clients
+				Expect(clients).To(HaveLen(4))
+				Expect(clients[3].Id).To(Equal(uint64(4)))
+				Expect(clients[3].LowWatermark).To(Equal(uint64(100)))
+
+				// Only the first epoch, at its planned expiration, should
+				// have ended, client reconfigurations do not end epochs.
+				Expect(node.PlaybackNode.StateMachine.Status().EpochTracker.LastActiveEpoch).To(Equal(uint64(2)))
+			}
+		})
+	})
+
+	When("the number of buckets is changed by reconfiguration", func() {
+		BeforeEach(func() {
+			newConfig := proto.Clone(recorder.NetworkState.Config).(*msgs.NetworkState_Config)
+			newConfig.NumberOfBuckets = 2
+			recorder.ReconfigPoints = []*ReconfigPoint{
+				{
+					ClientID: 0,
+					ReqNo:    10,
+					Reconfiguration: &msgs.Reconfiguration{
+						Type: &msgs.Reconfiguration_NewConfig{
+							NewConfig: newConfig,
+						},
+					},
+				},
+			}
+		})
+
+		It("delivers all requests under the new configuration", func() {
+			_, err := recording.DrainClients(50000)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range recording.Nodes {
+				Expect(node.State.LastCheckpoint().NetworkState.Config.NumberOfBuckets).To(Equal(int32(2)))
+			}
+		})
+	})
+
+	When("a node is removed by reconfiguration", func() {
+		BeforeEach(func() {
+			recorder = BasicRecorder(5, 4, 100)
+			newConfig := proto.Clone(recorder.NetworkState.Config).(*msgs.NetworkState_Config)
+			newConfig.Nodes = []uint64{0, 1, 2, 3}
+			newConfig.NumberOfBuckets = 4
+			newConfig.CheckpointInterval = 20
+			newConfig.MaxEpochLength = 200
+			recorder.ReconfigPoints = []*ReconfigPoint{
+				{
+					ClientID: 0,
+					ReqNo:    10,
+					Reconfiguration: &msgs.Reconfiguration{
+						Type: &msgs.Reconfiguration_NewConfig{
+							NewConfig: newConfig,
+						},
+					},
+				},
+			}
+		})
+
+		It("delivers all requests among the remaining nodes", func() {
+			_, err := recording.DrainClients(50000)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(recording.Nodes[4].IsMember()).To(BeFalse())
+			for _, node := range recording.Nodes[:4] {
+				Expect(node.State.LastCheckpoint().NetworkState.Config.Nodes).To(Equal([]uint64{0, 1, 2, 3}))
+			}
+		})
+	})
+
+	When("a node is added by reconfiguration", func() {
+		BeforeEach(func() {
+			newNodeConfig := proto.Clone(recorder.RecorderNodeConfigs[3].InitParms).(*state.EventInitialParameters)
+			newNodeConfig.Id = 4
+			recorder.RecorderNodeConfigs = append(recorder.RecorderNodeConfigs, &RecorderNodeConfig{
+				InitParms:    newNodeConfig,
+				RuntimeParms: recorder.RecorderNodeConfigs[3].RuntimeParms,
+			})
+
+			newConfig := proto.Clone(recorder.NetworkState.Config).(*msgs.NetworkState_Config)
+			newConfig.Nodes = []uint64{0, 1, 2, 3, 4}
+			newConfig.F = 1
+			newConfig.NumberOfBuckets = 5
+			recorder.ReconfigPoints = []*ReconfigPoint{
+				{
+					ClientID: 0,
+					ReqNo:    10,
+					Reconfiguration: &msgs.Reconfiguration{
+						Type: &msgs.Reconfiguration_NewConfig{
+							NewConfig: newConfig,
+						},
+					},
+				},
+			}
+		})
+
+		It("catches the new node up through state transfer and delivers all requests", func() {
+			_, err := recording.DrainClients(50000)
+			Expect(err).NotTo(HaveOccurred())
+
+			newNode := recording.Nodes[4]
+			Expect(newNode.IsMember()).To(BeTrue())
+			for _, client := range newNode.State.LastCheckpoint().NetworkState.Clients {
+				Expect(client.LowWatermark).To(Equal(uint64(100)))
+			}
+		})
+	})
+
+	When("the fault tolerance is reduced by reconfiguration", func() {
+		BeforeEach(func() {
+			// The weak quorum shrinks from two nodes to one
+			newConfig := proto.Clone(recorder.NetworkState.Config).(*msgs.NetworkState_Config)
+			newConfig.F = 0
+			recorder.ReconfigPoints = []*ReconfigPoint{
+				{
+					ClientID: 0,
+					ReqNo:    10,
+					Reconfiguration: &msgs.Reconfiguration{
+						Type: &msgs.Reconfiguration_NewConfig{
+							NewConfig: newConfig,
+						},
+					},
+				},
+			}
+		})
+
+		It("delivers all requests under the new quorum sizes", func() {
+			_, err := recording.DrainClients(50000)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range recording.Nodes {
+				Expect(node.State.LastCheckpoint().NetworkState.Config.F).To(Equal(int32(0)))
+			}
+		})
+	})
+
+	When("nodes are added and the fault tolerance is raised by reconfiguration", func() {
+		BeforeEach(func() {
+			// The weak quorum grows from two nodes to three, and
+			// the intersection quorum from three nodes to five.
+			newConfig := proto.Clone(recorder.NetworkState.Config).(*msgs.NetworkState_Config)
+			for id := uint64(4); id < 7; id++ {
+				newNodeConfig := proto.Clone(recorder.RecorderNodeConfigs[3].InitParms).(*state.EventInitialParameters)
+				newNodeConfig.Id = id
+				recorder.RecorderNodeConfigs = append(recorder.RecorderNodeConfigs, &RecorderNodeConfig{
+					InitParms:    newNodeConfig,
+					RuntimeParms: recorder.RecorderNodeConfigs[3].RuntimeParms,
+				})
+				newConfig.Nodes = append(newConfig.Nodes, id)
+			}
+			newConfig.F = 2
+			newConfig.NumberOfBuckets = 7
+			newConfig.CheckpointInterval = 35
+			newConfig.MaxEpochLength = 350
+			recorder.ReconfigPoints = []*ReconfigPoint{
+				{
+					ClientID: 0,
+					ReqNo:    10,
+					Reconfiguration: &msgs.Reconfiguration{
+						Type: &msgs.Reconfiguration_NewConfig{
+							NewConfig: newConfig,
+						},
+					},
+				},
+			}
+		})
+
+		It("delivers all requests under the new quorum sizes", func() {
+			_, err := recording.DrainClients(100000)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range recording.Nodes {
+				Expect(node.IsMember()).To(BeTrue())
+				config := node.State.LastCheckpoint().NetworkState.Config
+				Expect(config.Nodes).To(Equal([]uint64{0, 1, 2, 3, 4, 5, 6}))
+				Expect(config.F).To(Equal(int32(2)))
+			}
+		})
+	})
+
 	When("a larger batch size is used", func() {
 		BeforeEach(func() {
 			for _, nodeConfig := range recorder.RecorderNodeConfigs {
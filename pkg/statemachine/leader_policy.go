@@ -0,0 +1,168 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statemachine
+
+import (
+	"github.com/pkg/errors"
+)
+
+// LeaderPolicy selects the set of bucket leaders this node will propose
+// for a new epoch, should it be the primary for that epoch.  The state
+// machine must be deterministic, so implementations must base their
+// selection only on the supplied EpochHistory.  The selected leaders must
+// satisfy ValidateLeaders, otherwise the DefaultLeaderPolicy is used instead.
+type LeaderPolicy interface {
+	Leaders(history *EpochHistory) []uint64
+}
+
+// EpochHistory summarizes what this node observed of the epoch preceding
+// the one for which leaders are being selected.
+type EpochHistory struct {
+	// NewEpoch is the number of the epoch leaders are being selected for.
+	NewEpoch uint64
+
+	// Nodes is the set of nodes in the current network configuration.
+	Nodes []uint64
+
+	// LastEpoch is the number of the epoch which preceded NewEpoch,
+	// regardless of whether that epoch ever became active.
+	LastEpoch uint64
+
+	// LastLeaders is the leader set of the most recent epoch to become active.
+	LastLeaders []uint64
+
+	// Graceful is true if LastEpoch ended by reaching its planned expiration,
+	// rather than because of suspicion or a timeout.
+	Graceful bool
+
+	// Suspects is the set of nodes known to have suspected LastEpoch.
+	Suspects []uint64
+
+	// Responsive is the set of nodes whose epoch change messages for
+	// NewEpoch have been acknowledged by a quorum of the network.
+	Responsive []uint64
+}
+
+// defaultReadmitInterval is the ReadmitInterval used by the
+// DefaultLeaderPolicy when none is set.
+const defaultReadmitInterval = 4
+
+// DefaultLeaderPolicy is the LeaderPolicy used when none is configured.  When
+// the previous epoch ended gracefully, every node is made a leader.  When it
+// ended ungracefully, the leaders of the last active epoch are retained,
+// except for the primary of the failed epoch and any leader which has not
+// been heard from since (by way of a suspect or epoch change message), as
+// it has likely crashed.  The primary of the new epoch is always a leader.
+//
+// So that a node which is slow, but correct, is not excluded until an epoch
+// happens to end gracefully, nodes removed from the leader set in earlier
+// epochs are readmitted once every ReadmitInterval epochs, provided they
+// are responsive.
+type DefaultLeaderPolicy struct {
+	// ReadmitInterval is the number of epochs between readmissions of
+	// responsive nodes which are not leaders of the last active epoch.
+	// If zero, an interval of 4 epochs is used.
+	ReadmitInterval uint64
+}
+
+func (dlp DefaultLeaderPolicy) Leaders(history *EpochHistory) []uint64 {
+	primary := epochPrimary(history.NewEpoch, history.Nodes)
+
+	if history.Graceful {
+		return history.Nodes
+	}
+
+	failedPrimary := epochPrimary(history.LastEpoch, history.Nodes)
+
+	interval := dlp.ReadmitInterval
+	if interval == 0 {
+		interval = defaultReadmitInterval
+	}
+
+	// Readmit at the first epoch of each interval, even if the epoch
+	// change skipped over it.
+	readmit := history.NewEpoch/interval > history.LastEpoch/interval
+
+	responsive := map[uint64]struct{}{}
+	alive := map[uint64]struct{}{}
+	for _, id := range history.Responsive {
+		responsive[id] = struct{}{}
+		alive[id] = struct{}{}
+	}
+	for _, id := range history.Suspects {
+		alive[id] = struct{}{}
+	}
+
+	lastLeaders := map[uint64]struct{}{}
+	for _, id := range history.LastLeaders {
+		lastLeaders[id] = struct{}{}
+	}
+
+	var leaders []uint64
+	for _, id := range history.Nodes {
+		if id == primary {
+			leaders = append(leaders, id)
+			continue
+		}
+
+		if id == failedPrimary {
+			continue
+		}
+
+		if _, ok := lastLeaders[id]; !ok && len(lastLeaders) > 0 {
+			if _, ok := responsive[id]; !ok || !readmit {
+				continue
+			}
+		}
+
+		if _, ok := alive[id]; !ok {
+			continue
+		}
+
+		leaders = append(leaders, id)
+	}
+
+	return leaders
+}
+
+// ValidateLeaders checks that a leader set selected for the new epoch of the
+// history is usable.  It must be non-empty, include the primary of the new
+// epoch, and contain only nodes of the network configuration, each at most once.
+func ValidateLeaders(history *EpochHistory, leaders []uint64) error {
+	if len(leaders) == 0 {
+		return errors.New("leader set is empty")
+	}
+
+	members := map[uint64]struct{}{}
+	for _, id := range history.Nodes {
+		members[id] = struct{}{}
+	}
+
+	primary := epochPrimary(history.NewEpoch, history.Nodes)
+	hasPrimary := false
+	seen := map[uint64]struct{}{}
+	for _, id := range leaders {
+		if _, ok := members[id]; !ok {
+			return errors.Errorf("leader %d is not a member of the network", id)
+		}
+
+		if _, ok := seen[id]; ok {
+			return errors.Errorf("leader %d is included more than once", id)
+		}
+		seen[id] = struct{}{}
+
+		if id == primary {
+			hasPrimary = true
+		}
+	}
+
+	if !hasPrimary {
+		return errors.Errorf("leader set omits the epoch primary %d", primary)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statemachine_test
+
+import (
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft/pkg/statemachine"
+)
+
+var _ = DescribeTable("DefaultLeaderPolicy",
+	func(history *statemachine.EpochHistory, expectedLeaders []uint64) {
+		if history.Nodes == nil {
+			history.Nodes = []uint64{0, 1, 2, 3}
+		}
+		leaders := statemachine.DefaultLeaderPolicy{}.Leaders(history)
+		Expect(leaders).To(Equal(expectedLeaders))
+	},
+	Entry("restores all nodes after a graceful epoch end", &statemachine.EpochHistory{
+		NewEpoch:    5,
+		LastEpoch:   4,
+		LastLeaders: []uint64{1, 2},
+		Graceful:    true,
+	}, []uint64{0, 1, 2, 3}),
+
+	Entry("drops the primary of an ungracefully ended epoch", &statemachine.EpochHistory{
+		NewEpoch:    2,
+		LastEpoch:   1,
+		LastLeaders: []uint64{0, 1, 2, 3},
+		Responsive:  []uint64{0, 1, 2, 3},
+	}, []uint64{0, 2, 3}),
+
+	Entry("drops leaders which have not been heard from", &statemachine.EpochHistory{
+		NewEpoch:    2,
+		LastEpoch:   1,
+		LastLeaders: []uint64{0, 1, 2, 3},
+		Responsive:  []uint64{1, 2},
+	}, []uint64{2}),
+
+	Entry("retains leaders which sent suspicions", &statemachine.EpochHistory{
+		NewEpoch:    6,
+		LastEpoch:   5,
+		LastLeaders: []uint64{0, 1, 2, 3},
+		Suspects:    []uint64{0, 3},
+		Responsive:  []uint64{2},
+	}, []uint64{0, 2, 3}),
+
+	Entry("does not restore leaders removed in prior epochs", &statemachine.EpochHistory{
+		NewEpoch:    7,
+		LastEpoch:   6,
+		LastLeaders: []uint64{0, 3},
+		Responsive:  []uint64{0, 1, 2, 3},
+	}, []uint64{0, 3}),
+
+	Entry("readmits responsive nodes removed in prior epochs at each interval", &statemachine.EpochHistory{
+		NewEpoch:    8,
+		LastEpoch:   7,
+		LastLeaders: []uint64{0, 2},
+		Responsive:  []uint64{0, 1, 2},
+	}, []uint64{0, 1, 2}),
+
+	Entry("readmits when the epoch change skips the start of an interval", &statemachine.EpochHistory{
+		NewEpoch:    9,
+		LastEpoch:   6,
+		LastLeaders: []uint64{1, 2},
+		Responsive:  []uint64{0, 1, 2, 3},
+	}, []uint64{0, 1, 3}),
+
+	Entry("always includes the new primary", &statemachine.EpochHistory{
+		NewEpoch:    3,
+		LastEpoch:   2,
+		LastLeaders: []uint64{0, 1},
+		Responsive:  []uint64{0, 1, 2, 3},
+	}, []uint64{0, 1, 3}),
+
+	Entry("selects primaries by position when node IDs are not contiguous", &statemachine.EpochHistory{
+		Nodes:       []uint64{0, 1, 2, 5},
+		NewEpoch:    3,
+		LastEpoch:   2,
+		LastLeaders: []uint64{0, 1, 2, 5},
+		Responsive:  []uint64{0, 1, 2, 5},
+	}, []uint64{0, 1, 5}),
+)
+
+var _ = DescribeTable("ValidateLeaders",
+	func(leaders []uint64, expectedErr string) {
+		history := &statemachine.EpochHistory{
+			NewEpoch: 3,
+			Nodes:    []uint64{0, 1, 2, 5},
+		}
+		err := statemachine.ValidateLeaders(history, leaders)
+		if expectedErr == "" {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+		Expect(err).To(MatchError(expectedErr))
+	},
+	Entry("accepts leaders which include the primary", []uint64{1, 5}, ""),
+	Entry("rejects an empty leader set", []uint64{}, "leader set is empty"),
+	Entry("rejects a leader set without the primary", []uint64{0, 1, 2}, "leader set omits the epoch primary 5"),
+	Entry("rejects leaders which are not members", []uint64{3, 5}, "leader 3 is not a member of the network"),
+	Entry("rejects duplicate leaders", []uint64{5, 5}, "leader 5 is included more than once"),
+)
@@ -31,22 +31,7 @@ func newOutstandingReqs(clientTracker *clientTracker, networkState *msgs.Network
 		ao.buckets[i] = bo
 
 		for _, client := range networkState.Clients {
-			var firstUncommitted uint64
-			for j := 0; j < numBuckets; j++ {
-				reqNo := client.LowWatermark + uint64(j)
-				if clientReqToBucket(client.Id, reqNo, networkState.Config) == i {
-					firstUncommitted = reqNo
-					break
-				}
-			}
-
-			cors := &clientOutstandingReqs{
-				nextReqNo:  firstUncommitted,
-				numBuckets: uint64(networkState.Config.NumberOfBuckets),
-				client:     client,
-			}
-			cors.skipPreviouslyCommitted()
-
+			cors := newClientOutstandingReqs(i, client, networkState.Config)
 			logger.Log(LevelDebug, "initializing outstanding reqs for client", "client_id", client.Id, "bucket_id", i, "low_watermark", client.LowWatermark, "next_req_no", cors.nextReqNo)
 			bo.clients[client.Id] = cors
 		}
@@ -74,9 +59,29 @@ type clientOutstandingReqs struct {
 	client     *msgs.NetworkState_Client
 }
 
+func newClientOutstandingReqs(bucket bucketID, client *msgs.NetworkState_Client, networkConfig *msgs.NetworkState_Config) *clientOutstandingReqs {
+	var firstUncommitted uint64
+	for j := 0; j < int(networkConfig.NumberOfBuckets); j++ {
+		reqNo := client.LowWatermark + uint64(j)
+		if clientReqToBucket(client.Id, reqNo, networkConfig) == bucket {
+			firstUncommitted = reqNo
+			break
+		}
+	}
+
+	cors := &clientOutstandingReqs{
+		nextReqNo:  firstUncommitted,
+		numBuckets: uint64(networkConfig.NumberOfBuckets),
+		client:     client,
+	}
+	cors.skipPreviouslyCommitted()
+
+	return cors
+}
+
 func (cors *clientOutstandingReqs) skipPreviouslyCommitted() {
 	for {
-		if !isCommitted(cors.nextReqNo, cors.client) {
+		if !IsCommitted(cors.nextReqNo, cors.client) {
 			break
 		}
 
@@ -102,6 +107,32 @@ func (ao *allOutstandingReqs) advanceRequests() *ActionList {
 	return actions
 }
 
+// reconfigureClients begins tracking the next request for any client which
+// has been added, and stops tracking the next request for any client which
+// has been removed, so that any batch including a request from a removed
+// client is rejected.
+func (ao *allOutstandingReqs) reconfigureClients(clientStates []*msgs.NetworkState_Client, networkConfig *msgs.NetworkState_Config, removed func(clientID uint64) bool) {
+	for bucket, bo := range ao.buckets {
+		for clientID := range bo.clients {
+			if removed(clientID) {
+				delete(bo.clients, clientID)
+			}
+		}
+
+		for _, client := range clientStates {
+			if _, ok := bo.clients[client.Id]; !ok {
+				bo.clients[client.Id] = newClientOutstandingReqs(bucket, client, networkConfig)
+			}
+		}
+	}
+
+	for key, ack := range ao.correctRequests {
+		if removed(ack.ClientId) {
+			delete(ao.correctRequests, key)
+		}
+	}
+}
+
 // TODO, bucket probably can/should be stored in the *sequence
 func (ao *allOutstandingReqs) applyAcks(bucket bucketID, seq *sequence, batch []*msgs.RequestAck) (*ActionList, error) {
 	bo, ok := ao.buckets[bucket]
@@ -139,6 +139,16 @@ func (p *persisted) addECEntry(ecEntry *msgs.ECEntry) *ActionList {
 	return p.appendLogEntry(d)
 }
 
+func (p *persisted) addFEntry(fEntry *msgs.FEntry) *ActionList {
+	d := &msgs.Persistent{
+		Type: &msgs.Persistent_FEntry{
+			FEntry: fEntry,
+		},
+	}
+
+	return p.appendLogEntry(d)
+}
+
 func (p *persisted) addTEntry(tEntry *msgs.TEntry) *ActionList {
 	d := &msgs.Persistent{
 		Type: &msgs.Persistent_TEntry{
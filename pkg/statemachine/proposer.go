@@ -28,7 +28,10 @@ type proposer struct {
 
 type proposalBucket struct {
 	requestCount       uint32
+	maxBytes           uint32
+	timeoutTicks       uint32
 	pending            []*clientRequest
+	pendingBytes       uint64
 	bucketID           bucketID
 	checkpointInterval uint64
 
@@ -45,6 +48,11 @@ type proposalBucket struct {
 	// when we advance beyond that checkpoint sequence, we push this list onto the back
 	// of the ready list and re-initialize this list.
 	nextReadyList *list.List
+
+	// ticks is incremented by one each tick, requests record the tick at which
+	// they became ready, so that the batch timeout applies from then, even if
+	// the request waited in the readyList for earlier batches to be cut.
+	ticks uint64
 }
 
 func newProposer(baseCheckpoint uint64, checkpointInterval uint64, myConfig *state.EventInitialParameters, clientTracker *clientTracker, buckets map[bucketID]nodeID) *proposer {
@@ -60,6 +68,8 @@ func newProposer(baseCheckpoint uint64, checkpointInterval uint64, myConfig *sta
 			readyList:          list.New(),
 			nextReadyList:      list.New(),
 			requestCount:       myConfig.BatchSize,
+			maxBytes:           myConfig.MaxBatchBytes,
+			timeoutTicks:       myConfig.BatchTimeoutTicks,
 			pending:            make([]*clientRequest, 0, 1), // TODO, might be interesting to play with not preallocating for performance reasons
 		}
 	}
@@ -112,12 +122,49 @@ func (p *proposer) advance(toSeqNo uint64) {
 	}
 }
 
+// removeClients drops any queued requests from clients which have been
+// removed, as they may no longer be proposed.
+func (p *proposer) removeClients(removed func(clientID uint64) bool) {
+	for _, prb := range p.proposalBuckets {
+		pending := prb.pending[:0]
+		for _, cr := range prb.pending {
+			if !removed(cr.ack.ClientId) {
+				pending = append(pending, cr)
+			}
+		}
+		prb.pending = pending
+		prb.pendingBytes = 0
+		for _, cr := range prb.pending {
+			prb.pendingBytes += uint64(cr.ack.Size)
+		}
+
+		for _, l := range []*list.List{prb.readyList, prb.nextReadyList} {
+			var next *list.Element
+			for el := l.Front(); el != nil; el = next {
+				next = el.Next()
+				if removed(el.Value.(*clientRequest).ack.ClientId) {
+					l.Remove(el)
+				}
+			}
+		}
+	}
+}
+
+// tick ages the ready requests of each bucket so that a partial
+// batch may be cut once the batch timeout expires.
+func (p *proposer) tick() {
+	for _, prb := range p.proposalBuckets {
+		prb.ticks++
+	}
+}
+
 func (p *proposer) proposalBucket(bucketID bucketID) *proposalBucket {
 	return p.proposalBuckets[bucketID]
 }
 
 func (prb *proposalBucket) queueRequest(validAfterSeqNo uint64, cr *clientRequest) {
 	if prb.currentCheckpoint >= validAfterSeqNo {
+		cr.readyTick = prb.ticks
 		prb.readyList.PushBack(cr)
 	} else {
 		assertEqual(validAfterSeqNo, prb.currentCheckpoint+prb.checkpointInterval, "requests should never ready beyond the next checkpoint interval")
@@ -128,6 +175,9 @@ func (prb *proposalBucket) queueRequest(validAfterSeqNo uint64, cr *clientReques
 func (prb *proposalBucket) advance(toSeqNo uint64) {
 	if toSeqNo >= prb.currentCheckpoint+prb.checkpointInterval {
 		prb.currentCheckpoint += prb.checkpointInterval
+		for el := prb.nextReadyList.Front(); el != nil; el = el.Next() {
+			el.Value.(*clientRequest).readyTick = prb.ticks
+		}
 		prb.readyList.PushBackList(prb.nextReadyList)
 		prb.nextReadyList = list.New()
 	}
@@ -137,11 +187,40 @@ func (prb *proposalBucket) advance(toSeqNo uint64) {
 			break
 		}
 
-		prb.pending = append(
-			prb.pending,
-			prb.readyList.Remove(prb.readyList.Front()).(*clientRequest),
-		)
+		if prb.nextExceedsBytes() {
+			break
+		}
+
+		cr := prb.readyList.Remove(prb.readyList.Front()).(*clientRequest)
+		prb.pending = append(prb.pending, cr)
+		prb.pendingBytes += uint64(cr.ack.Size)
+	}
+}
+
+// nextExceedsBytes returns whether adding the next ready request to
+// the pending requests would exceed the maximum batch size in bytes.
+// A request is always allowed into an empty batch, so that requests
+// larger than the maximum may still be proposed.
+func (prb *proposalBucket) nextExceedsBytes() bool {
+	if prb.maxBytes == 0 || len(prb.pending) == 0 || prb.readyList.Len() == 0 {
+		return false
 	}
+
+	cr := prb.readyList.Front().Value.(*clientRequest)
+	return prb.pendingBytes+uint64(cr.ack.Size) > uint64(prb.maxBytes)
+}
+
+// full returns whether the pending requests may grow no further.
+func (prb *proposalBucket) full() bool {
+	if uint32(len(prb.pending)) >= prb.requestCount {
+		return true
+	}
+
+	if prb.maxBytes == 0 {
+		return false
+	}
+
+	return prb.pendingBytes >= uint64(prb.maxBytes) || prb.nextExceedsBytes()
 }
 
 func (prb *proposalBucket) hasOutstanding(forSeqNo uint64) bool {
@@ -151,11 +230,22 @@ func (prb *proposalBucket) hasOutstanding(forSeqNo uint64) bool {
 
 func (prb *proposalBucket) hasPending(forSeqNo uint64) bool {
 	prb.advance(forSeqNo)
-	return len(prb.pending) > 0 && uint32(len(prb.pending)) == prb.requestCount
+	if len(prb.pending) == 0 {
+		return false
+	}
+
+	if prb.full() {
+		return true
+	}
+
+	// The oldest pending request is first, as requests are pending in
+	// the order they became ready.
+	return prb.timeoutTicks != 0 && prb.ticks-prb.pending[0].readyTick >= uint64(prb.timeoutTicks)
 }
 
 func (prb *proposalBucket) next() []*clientRequest {
 	result := prb.pending
 	prb.pending = make([]*clientRequest, 0, prb.requestCount)
+	prb.pendingBytes = 0
 	return result
 }
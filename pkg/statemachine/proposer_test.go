@@ -0,0 +1,118 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statemachine
+
+import (
+	"container/list"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+)
+
+var _ = Describe("proposalBucket", func() {
+	var (
+		prb *proposalBucket
+	)
+
+	queue := func(reqNo uint64, size uint32) {
+		prb.queueRequest(0, &clientRequest{
+			ack: &msgs.RequestAck{
+				ClientId: 1,
+				ReqNo:    reqNo,
+				Size:     size,
+			},
+		})
+	}
+
+	BeforeEach(func() {
+		prb = &proposalBucket{
+			requestCount:       3,
+			checkpointInterval: 5,
+			readyList:          list.New(),
+			nextReadyList:      list.New(),
+		}
+	})
+
+	It("cuts a batch once the request count is reached", func() {
+		queue(0, 10)
+		queue(1, 10)
+		Expect(prb.hasPending(1)).To(BeFalse())
+
+		queue(2, 10)
+		Expect(prb.hasPending(1)).To(BeTrue())
+		Expect(prb.next()).To(HaveLen(3))
+		Expect(prb.hasOutstanding(1)).To(BeFalse())
+	})
+
+	When("a maximum batch size in bytes is set", func() {
+		BeforeEach(func() {
+			prb.maxBytes = 25
+		})
+
+		It("cuts a batch before the next request would exceed it", func() {
+			queue(0, 10)
+			queue(1, 10)
+			Expect(prb.hasPending(1)).To(BeFalse())
+
+			queue(2, 10)
+			Expect(prb.hasPending(1)).To(BeTrue())
+			Expect(prb.next()).To(HaveLen(2))
+			Expect(prb.hasOutstanding(1)).To(BeTrue())
+			Expect(prb.hasPending(1)).To(BeFalse())
+		})
+
+		It("cuts a request larger than the maximum by itself", func() {
+			queue(0, 30)
+			queue(1, 10)
+			Expect(prb.hasPending(1)).To(BeTrue())
+			Expect(prb.next()).To(HaveLen(1))
+		})
+	})
+
+	When("a batch timeout is set", func() {
+		BeforeEach(func() {
+			prb.timeoutTicks = 2
+		})
+
+		It("cuts a partial batch once the timeout expires", func() {
+			p := &proposer{
+				proposalBuckets: map[bucketID]*proposalBucket{0: prb},
+			}
+
+			p.tick()
+			queue(0, 10)
+			Expect(prb.hasPending(1)).To(BeFalse())
+			p.tick()
+			Expect(prb.hasPending(1)).To(BeFalse())
+			p.tick()
+			Expect(prb.hasPending(1)).To(BeTrue())
+			Expect(prb.next()).To(HaveLen(1))
+
+			queue(1, 10)
+			Expect(prb.hasPending(1)).To(BeFalse())
+		})
+
+		It("ages requests from when they became ready", func() {
+			p := &proposer{
+				proposalBuckets: map[bucketID]*proposalBucket{0: prb},
+			}
+
+			for i := uint64(0); i < 4; i++ {
+				queue(i, 10)
+			}
+			Expect(prb.hasPending(1)).To(BeTrue())
+			Expect(prb.next()).To(HaveLen(3))
+
+			p.tick()
+			p.tick()
+			Expect(prb.hasPending(1)).To(BeTrue())
+			Expect(prb.next()).To(HaveLen(1))
+		})
+	})
+})
@@ -97,6 +97,10 @@ const (
 type StateMachine struct {
 	Logger Logger
 
+	// LeaderPolicy selects the leaders this node proposes in a new epoch.
+	// If nil, the DefaultLeaderPolicy is used.
+	LeaderPolicy LeaderPolicy
+
 	state stateMachineState
 
 	myConfig               *state.EventInitialParameters
@@ -135,6 +139,12 @@ func (sm *StateMachine) initialize(parameters *state.EventInitialParameters) {
 	sm.commitState = newCommitState(sm.persisted, sm.Logger)
 	sm.clientHashDisseminator = newClientHashDisseminator(sm.nodeBuffers, sm.myConfig, sm.Logger, sm.clientTracker)
 	sm.batchTracker = newBatchTracker(sm.persisted)
+
+	leaderPolicy := sm.LeaderPolicy
+	if leaderPolicy == nil {
+		leaderPolicy = DefaultLeaderPolicy{}
+	}
+
 	sm.epochTracker = newEpochTracker(
 		sm.persisted,
 		sm.nodeBuffers,
@@ -145,6 +155,7 @@ func (sm *StateMachine) initialize(parameters *state.EventInitialParameters) {
 		sm.batchTracker,
 		sm.clientTracker,
 		sm.clientHashDisseminator,
+		leaderPolicy,
 	)
 
 }
@@ -186,6 +197,9 @@ func (sm *StateMachine) applyEvent(stateEvent *state.Event) *ActionList {
 		assertInitialized()
 		actions.concat(sm.clientHashDisseminator.tick())
 		actions.concat(sm.epochTracker.tick())
+		correctSeqNo, correctValue := sm.checkpointTracker.highestCorrect()
+		actions.concat(sm.commitState.retryTransfer(correctSeqNo, correctValue))
+		actions.concat(sm.commitState.catchUp(nodeID(sm.myConfig.Id), correctSeqNo, correctValue))
 	case *state.Event_Step:
 		assertInitialized()
 		actions.concat(sm.step(
@@ -204,8 +218,12 @@ func (sm *StateMachine) applyEvent(stateEvent *state.Event) *ActionList {
 			event.RequestPersisted.RequestAck,
 		))
 	case *state.Event_StateTransferFailed:
+		assertInitialized()
 		sm.Logger.Log(LevelDebug, "state transfer failed", "seq_no", event.StateTransferFailed.SeqNo)
-		panic("XXX handle state transfer failure")
+		sm.commitState.transferFailed(
+			event.StateTransferFailed.SeqNo,
+			event.StateTransferFailed.CheckpointValue,
+		)
 	case *state.Event_StateTransferComplete:
 		assertEqualf(sm.commitState.transferring, true, "state transfer event received but the state machine did not request transfer")
 
@@ -244,6 +262,12 @@ func (sm *StateMachine) applyEvent(stateEvent *state.Event) *ActionList {
 			sm.batchTracker.truncate(newLow - uint64(sm.checkpointTracker.networkConfig.CheckpointInterval))
 		}
 		actions.concat(sm.epochTracker.moveLowWatermark(newLow))
+
+		if changeSeqNo := sm.commitState.configChangeSeqNo; changeSeqNo != 0 && newLow >= changeSeqNo {
+			// A NewConfig reconfiguration committed, and the checkpoint
+			// which applies it is now stable.
+			actions.concat(sm.reconfigure())
+		}
 	}
 
 	for {
@@ -282,6 +306,25 @@ func (sm *StateMachine) reinitialize() *ActionList {
 	return actions.concat(sm.epochTracker.reinitialize())
 }
 
+// reconfigure is invoked once a checkpoint which changes the network configuration
+// becomes stable.  No sequence beyond this checkpoint may commit under the old
+// configuration, so we terminate the last active epoch, and reinitialize under the
+// new configuration, which begins an epoch change among the new set of nodes.
+func (sm *StateMachine) reconfigure() *ActionList {
+	lastEpochConfig := sm.epochTracker.lastActiveEpoch
+	if activeEpoch := sm.epochTracker.currentEpoch.activeEpoch; activeEpoch != nil {
+		lastEpochConfig = activeEpoch.epochConfig
+	}
+
+	sm.Logger.Log(LevelInfo, "reconfiguring at stable checkpoint", "seq_no", sm.commitState.stopAtSeqNo, "last_epoch", lastEpochConfig.Number)
+
+	actions := sm.persisted.addFEntry(&msgs.FEntry{
+		EndsEpochConfig: lastEpochConfig,
+	})
+
+	return actions.concat(sm.reinitialize())
+}
+
 func (sm *StateMachine) recoverLog() *ActionList {
 	var lastCEntry *msgs.CEntry
 
@@ -303,6 +346,14 @@ func (sm *StateMachine) recoverLog() *ActionList {
 }
 
 func (sm *StateMachine) step(source nodeID, msg *msgs.Msg) *ActionList {
+	if !isMember(source, sm.epochTracker.networkConfig) {
+		// Nodes outside of our current configuration may be joining the network,
+		// or may have been removed from it, either way, we have no use for their
+		// messages until we reinitialize under a configuration which includes them.
+		sm.Logger.Log(LevelDebug, "dropping message from node outside of network configuration", "source", source)
+		return &ActionList{}
+	}
+
 	actions := &ActionList{}
 	switch msg.Type.(type) {
 	case *msgs.Msg_RequestAck:
@@ -385,11 +436,18 @@ func (sm *StateMachine) processCheckpointResult(checkpointResult *state.EventChe
 		epochConfig = sm.epochTracker.currentEpoch.activeEpoch.epochConfig
 	}
 
-	prevStopAtSeqNo := sm.commitState.stopAtSeqNo
+	prevLowWatermark := sm.commitState.lowWatermark
 	actions.concat(sm.commitState.applyCheckpointResult(epochConfig, checkpointResult))
-	if prevStopAtSeqNo < sm.commitState.stopAtSeqNo {
+	if prevLowWatermark < sm.commitState.lowWatermark {
+		// Note, the result may include pending reconfigurations, in which
+		// case the clients allocate only through their intermediate high
+		// watermarks, but we must still allocate so that the allocations
+		// are contiguous across checkpoints.
 		sm.clientTracker.allocate(checkpointResult.SeqNo, checkpointResult.NetworkState)
 		actions.concat(sm.clientHashDisseminator.allocate(checkpointResult.SeqNo, checkpointResult.NetworkState))
+		if activeEpoch := sm.epochTracker.currentEpoch.activeEpoch; activeEpoch != nil {
+			activeEpoch.reconfigureClients(checkpointResult.NetworkState.Clients)
+		}
 	}
 
 	return actions
@@ -419,5 +477,6 @@ func (sm *StateMachine) Status() *status.StateMachine {
 		Buckets:       bucketStatus,
 		Checkpoints:   checkpoints,
 		NodeBuffers:   sm.nodeBuffers.status(),
+		StateTransfer: sm.commitState.status(),
 	}
 }
@@ -15,7 +15,9 @@ import (
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 )
 
-func isCommitted(reqNo uint64, clientState *msgs.NetworkState_Client) bool {
+// IsCommitted reports whether the given request number is committed
+// according to the client's state as of some checkpoint.
+func IsCommitted(reqNo uint64, clientState *msgs.NetworkState_Client) bool {
 	if reqNo < clientState.LowWatermark {
 		return true
 	}
@@ -112,6 +114,44 @@ func someCorrectQuorum(nc *msgs.NetworkState_Config) int {
 	return int(nc.F) + 1
 }
 
+// epochPrimary is the node responsible for constructing the new epoch
+// message for the given epoch number.  Node IDs need not be contiguous,
+// so the primary is selected by position in the node list.
+func epochPrimary(epochNumber uint64, nodes []uint64) uint64 {
+	return nodes[epochNumber%uint64(len(nodes))]
+}
+
+// isMember returns whether the given node is one of the nodes
+// of the network configuration.
+func isMember(id nodeID, nc *msgs.NetworkState_Config) bool {
+	for _, node := range nc.Nodes {
+		if nodeID(node) == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unionNodes returns the nodes in a followed by the nodes
+// in b which are not in a.
+func unionNodes(a, b []uint64) []uint64 {
+	result := append([]uint64{}, a...)
+	seen := map[uint64]struct{}{}
+	for _, id := range a {
+		seen[id] = struct{}{}
+	}
+
+	for _, id := range b {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		result = append(result, id)
+	}
+
+	return result
+}
+
 func clientReqToBucket(clientID, reqNo uint64, nc *msgs.NetworkState_Config) bucketID {
 	return bucketID((clientID + reqNo) % uint64(nc.NumberOfBuckets))
 }
@@ -80,6 +80,15 @@ type StateMachine struct {
 	Buckets       []*Bucket        `json:"buckets"`
 	Checkpoints   []*Checkpoint    `json:"checkpoints"`
 	ClientWindows []*ClientTracker `json:"client_tracker"`
+	StateTransfer *StateTransfer   `json:"state_transfer,omitempty"`
+}
+
+// StateTransfer is only populated while a state transfer is in progress.
+type StateTransfer struct {
+	SeqNo      uint64 `json:"seq_no"`
+	Value      []byte `json:"value"`
+	Failures   int    `json:"failures"`
+	RetryTicks int    `json:"retry_ticks"`
 }
 
 type Bucket struct {
@@ -156,12 +165,30 @@ type NodeBucket struct {
 }
 
 type ClientTracker struct {
-	ClientID      uint64   `json:"client_id"`
-	LowWatermark  uint64   `json:"low_watermark"`
-	HighWatermark uint64   `json:"high_watermark"`
-	Allocated     []uint64 `json:"allocated"`
+	ClientID      uint64 `json:"client_id"`
+	LowWatermark  uint64 `json:"low_watermark"`
+	HighWatermark uint64 `json:"high_watermark"`
+
+	// Allocated has an entry for each request number beginning at the low
+	// watermark, through the last request number which has been acked.  Each
+	// entry is one of ClientReqUnknown, ClientReqAcked, or ClientReqCommitted.
+	Allocated []uint64 `json:"allocated"`
 }
 
+const (
+	// ClientReqUnknown indicates no replica is known to have acked a request
+	// for this request number.
+	ClientReqUnknown uint64 = iota
+
+	// ClientReqAcked indicates some replica has acked a request for this
+	// request number, but it has not committed.
+	ClientReqAcked
+
+	// ClientReqCommitted indicates a request for this request number has
+	// committed.
+	ClientReqCommitted
+)
+
 func (s *StateMachine) Pretty() string {
 	var buffer bytes.Buffer
 	buffer.WriteString("===========================================\n")
@@ -186,6 +213,12 @@ func (s *StateMachine) Pretty() string {
 	buffer.WriteString("=====================\n")
 	buffer.WriteString("\n")
 
+	if s.StateTransfer != nil {
+		buffer.WriteString("=== State Transfer ===\n")
+		buffer.WriteString(fmt.Sprintf("Transferring to SeqNo=%d Value=%.4x Failures=%d RetryTicks=%d\n", s.StateTransfer.SeqNo, s.StateTransfer.Value, s.StateTransfer.Failures, s.StateTransfer.RetryTicks))
+		buffer.WriteString("\n")
+	}
+
 	hRule := func() {
 		for seqNo := s.LowWatermark; seqNo <= s.HighWatermark; seqNo += uint64(len(s.Buckets)) {
 			buffer.WriteString("--")
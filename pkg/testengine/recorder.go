@@ -177,6 +177,18 @@ type RecorderNode struct {
 	AwaitingClientProcessEvent bool
 }
 
+// IsMember returns whether the node is included in the network
+// configuration of its last checkpoint.
+func (rn *RecorderNode) IsMember() bool {
+	for _, id := range rn.State.LastCheckpoint().NetworkState.Config.Nodes {
+		if id == rn.Config.InitParms.Id {
+			return true
+		}
+	}
+
+	return false
+}
+
 type RecorderClient struct {
 	Config *ClientConfig
 	Hasher Hasher
@@ -266,12 +278,16 @@ func (ns *NodeState) Checkpoint(checkpoint *state.ActionCheckpoint) *state.Event
 		panic("asked to checkpoint for uncommitted sequence")
 	}
 
+	pendingReconfigurations := ns.PendingReconfigurations
+	ns.PendingReconfigurations = nil
+
 	return ns.Set(
 		checkpoint.SeqNo,
 		ns.ActiveHash.Sum(nil),
 		&msgs.NetworkState{
-			Config:  checkpoint.NetworkConfig,
-			Clients: checkpoint.ClientStates,
+			Config:                  checkpoint.NetworkConfig,
+			Clients:                 checkpoint.ClientStates,
+			PendingReconfigurations: pendingReconfigurations,
 		},
 	)
 }
@@ -548,17 +564,27 @@ func (r *Recording) Step() error {
 				for _, node := range r.Nodes {
 					el, ok := node.State.CheckpointsBySeqNo[t.StateTransfer.SeqNo]
 					if !ok {
-						// if no node has the state, networkState will be nil
-						// which signals to the state machine to try another target
 						continue
 					}
 
 					networkState = el.Value.(*state.EventCheckpointResult).NetworkState
 					break
 				}
-				r.EventLog.InsertStateEvent(
-					lastEvent.NodeId,
-					&state.Event{
+
+				var stateEvent *state.Event
+				if networkState == nil {
+					// No node has the state, so signal to the
+					// state machine that it should try again later
+					stateEvent = &state.Event{
+						Type: &state.Event_StateTransferFailed{
+							StateTransferFailed: &state.EventStateTransferFailed{
+								SeqNo:           t.StateTransfer.SeqNo,
+								CheckpointValue: t.StateTransfer.Value,
+							},
+						},
+					}
+				} else {
+					stateEvent = &state.Event{
 						Type: &state.Event_StateTransferComplete{
 							StateTransferComplete: &state.EventStateTransferComplete{
 								SeqNo:           t.StateTransfer.SeqNo,
@@ -566,7 +592,12 @@ func (r *Recording) Step() error {
 								NetworkState:    networkState,
 							},
 						},
-					},
+					}
+				}
+
+				r.EventLog.InsertStateEvent(
+					lastEvent.NodeId,
+					stateEvent,
 					int64(runtimeParms.StateTransferLatency),
 				)
 			default:
@@ -652,6 +683,8 @@ func (r *Recording) Step() error {
 }
 
 // DrainClients will execute the recording until all client requests have committed.
+// Nodes which are not members of the network configuration of their last checkpoint,
+// because they have been removed, or have yet to join, are not considered.
 // It will return with an error if the number of accumulated log entries exceeds timeout.
 // If any step returns an error, this function returns that error.
 func (r *Recording) DrainClients(timeout int) (count int, err error) {
@@ -671,6 +704,10 @@ func (r *Recording) DrainClients(timeout int) (count int, err error) {
 		allDone := true
 	outer:
 		for _, node := range r.Nodes {
+			if !node.IsMember() {
+				continue
+			}
+
 			for _, client := range node.State.LastCheckpoint().NetworkState.Clients {
 				if targetReqs[client.Id] != client.LowWatermark {
 					allDone = false
@@ -686,6 +723,10 @@ func (r *Recording) DrainClients(timeout int) (count int, err error) {
 		if count > timeout {
 			var errText string
 			for _, node := range r.Nodes {
+				if !node.IsMember() {
+					continue
+				}
+
 				for _, client := range node.State.LastCheckpoint().NetworkState.Clients {
 					if targetReqs[client.Id] != client.LowWatermark {
 						errText = fmt.Sprintf("(at least) node%d failed with client %d committing only through %d when expected %d", node.Config.InitParms.Id, client.Id, client.LowWatermark, targetReqs[client.Id])
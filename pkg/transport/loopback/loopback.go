@@ -0,0 +1,352 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package loopback provides an in-process implementation of the mirbft.Link
+// interface, so that a whole network of nodes may run within a single binary,
+// for instance in integration tests or local demos.  The network's latency,
+// loss, and partitions may be changed while it runs.
+package loopback
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+)
+
+// SourceMsg is a message delivered to a node, along with the node
+// which sent it.
+type SourceMsg struct {
+	Source uint64
+	Msg    *msgs.Msg
+}
+
+// LinkStats reports the messages sent from one node to another.
+type LinkStats struct {
+	// Pending is the number of messages sent but not yet delivered.
+	Pending int
+
+	// Delivered is the number of messages delivered to the destination.
+	Delivered uint64
+
+	// Dropped is the number of messages discarded because of injected
+	// loss or a partition.
+	Dropped uint64
+}
+
+// Transport connects the nodes with IDs zero through one less than the
+// number of nodes.  Each pair of nodes is connected by an unbounded queue,
+// so messages are never dropped because of a slow receiver, and messages
+// from one node to another are delivered in the order they were sent.
+// Messages are only ever dropped when loss or a partition is injected, and
+// each such drop is counted in the Stats of the link.  As these drops are
+// deliberate, and may be very frequent, they are logged only at debug level,
+// which the default logger discards, so the Stats are the only signal of them
+// unless a debug Logger is supplied.
+type Transport struct {
+	// Logger, if set, is used to report dropped messages.  If not set,
+	// mirbft.ConsoleWarnLogger is used, which reports only messages
+	// for unknown nodes, not injected loss or partitions.
+	Logger mirbft.Logger
+
+	mutex  sync.Mutex
+	rand   *rand.Rand
+	groups map[uint64]int
+	links  [][]*link
+	sinks  []chan SourceMsg
+	doneC  chan struct{}
+	wg     sync.WaitGroup
+}
+
+type link struct {
+	mutex     sync.Mutex
+	source    uint64
+	dest      uint64
+	latency   time.Duration
+	loss      float64
+	queue     []pendingMsg
+	delivered uint64
+	dropped   uint64
+	readyC    chan struct{}
+}
+
+type pendingMsg struct {
+	msg       *msgs.Msg
+	deliverAt time.Time
+}
+
+// New creates a transport for the given number of nodes.  The seed
+// determines which messages are dropped when loss is injected.
+func New(nodes int, seed int64) *Transport {
+	links := make([][]*link, nodes)
+	sinks := make([]chan SourceMsg, nodes)
+	for i := range links {
+		links[i] = make([]*link, nodes)
+		for j := range links[i] {
+			if i == j {
+				continue
+			}
+			links[i][j] = &link{
+				source: uint64(i),
+				dest:   uint64(j),
+				readyC: make(chan struct{}, 1),
+			}
+		}
+		sinks[i] = make(chan SourceMsg)
+	}
+
+	return &Transport{
+		rand:  rand.New(rand.NewSource(seed)),
+		links: links,
+		sinks: sinks,
+		doneC: make(chan struct{}),
+	}
+}
+
+// Link returns the mirbft.Link used by the given node to send.
+func (t *Transport) Link(source uint64) *Link {
+	return &Link{
+		Source:    source,
+		Transport: t,
+	}
+}
+
+// RecvC returns the channel on which messages for the given node
+// are delivered.
+func (t *Transport) RecvC(dest uint64) <-chan SourceMsg {
+	return t.sinks[int(dest)]
+}
+
+// Start begins delivering messages.
+func (t *Transport) Start() {
+	for _, sourceLinks := range t.links {
+		for _, l := range sourceLinks {
+			if l == nil {
+				continue
+			}
+
+			t.wg.Add(1)
+			go t.deliver(l)
+		}
+	}
+}
+
+// Stop stops delivering messages, and waits for the delivering
+// go routines to exit.  Any undelivered messages are discarded.
+func (t *Transport) Stop() {
+	close(t.doneC)
+	t.wg.Wait()
+}
+
+// Send queues the message for delivery from the source to the destination.
+// It never blocks.
+func (t *Transport) Send(source, dest uint64, msg *msgs.Msg) {
+	l := t.link(source, dest)
+	if l == nil {
+		t.logger().Log(mirbft.LevelWarn, "dropping message for unknown node", "source", source, "dest", dest)
+		return
+	}
+
+	t.mutex.Lock()
+	partitioned := t.groups != nil && t.groups[source] != t.groups[dest]
+	roll := t.rand.Float64()
+	t.mutex.Unlock()
+
+	l.mutex.Lock()
+	if partitioned || roll < l.loss {
+		l.dropped++
+		l.mutex.Unlock()
+		t.logger().Log(mirbft.LevelDebug, "dropping message", "source", source, "dest", dest, "partitioned", partitioned)
+		return
+	}
+
+	l.queue = append(l.queue, pendingMsg{
+		msg:       msg,
+		deliverAt: time.Now().Add(l.latency),
+	})
+	l.mutex.Unlock()
+
+	select {
+	case l.readyC <- struct{}{}:
+	default:
+	}
+}
+
+// SetLatency sets the delay before each subsequently sent message
+// is delivered, for every pair of nodes.
+func (t *Transport) SetLatency(latency time.Duration) {
+	t.eachLink(func(l *link) {
+		l.latency = latency
+	})
+}
+
+// SetLinkLatency sets the delay before each subsequently sent message
+// from the source to the destination is delivered.
+func (t *Transport) SetLinkLatency(source, dest uint64, latency time.Duration) {
+	t.withLink(source, dest, func(l *link) {
+		l.latency = latency
+	})
+}
+
+// SetLoss sets the probability that each subsequently sent message is
+// dropped, for every pair of nodes.
+func (t *Transport) SetLoss(rate float64) {
+	t.eachLink(func(l *link) {
+		l.loss = rate
+	})
+}
+
+// SetLinkLoss sets the probability that each subsequently sent message
+// from the source to the destination is dropped.
+func (t *Transport) SetLinkLoss(source, dest uint64, rate float64) {
+	t.withLink(source, dest, func(l *link) {
+		l.loss = rate
+	})
+}
+
+// Partition splits the nodes into the given groups.  Subsequently sent
+// messages are only delivered between nodes in the same group, and nodes
+// which are not in any group may not communicate with any other node.
+// Messages already sent are still delivered.
+func (t *Transport) Partition(groups ...[]uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.groups = map[uint64]int{}
+	for id := range t.links {
+		// Nodes not in any group are each in a group of their own
+		t.groups[uint64(id)] = len(groups) + id
+	}
+
+	for i, group := range groups {
+		for _, id := range group {
+			t.groups[id] = i
+		}
+	}
+}
+
+// Heal removes any partition.
+func (t *Transport) Heal() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.groups = nil
+}
+
+// Stats returns the statistics for messages sent from the source
+// to the destination.
+func (t *Transport) Stats(source, dest uint64) LinkStats {
+	var stats LinkStats
+	t.withLink(source, dest, func(l *link) {
+		stats = LinkStats{
+			Pending:   len(l.queue),
+			Delivered: l.delivered,
+			Dropped:   l.dropped,
+		}
+	})
+	return stats
+}
+
+func (t *Transport) logger() mirbft.Logger {
+	if t.Logger == nil {
+		return mirbft.ConsoleWarnLogger
+	}
+	return t.Logger
+}
+
+func (t *Transport) link(source, dest uint64) *link {
+	if source >= uint64(len(t.links)) || dest >= uint64(len(t.links)) {
+		return nil
+	}
+
+	return t.links[source][dest]
+}
+
+func (t *Transport) withLink(source, dest uint64, fn func(*link)) {
+	l := t.link(source, dest)
+	if l == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	fn(l)
+}
+
+func (t *Transport) eachLink(fn func(*link)) {
+	for _, sourceLinks := range t.links {
+		for _, l := range sourceLinks {
+			if l == nil {
+				continue
+			}
+
+			l.mutex.Lock()
+			fn(l)
+			l.mutex.Unlock()
+		}
+	}
+}
+
+// deliver sends the queued messages of the link to the destination
+// in order, once each message's latency has elapsed.
+func (t *Transport) deliver(l *link) {
+	defer t.wg.Done()
+	for {
+		l.mutex.Lock()
+		var next pendingMsg
+		ready := len(l.queue) > 0
+		if ready {
+			next = l.queue[0]
+		}
+		l.mutex.Unlock()
+
+		if !ready {
+			select {
+			case <-l.readyC:
+				continue
+			case <-t.doneC:
+				return
+			}
+		}
+
+		if delay := time.Until(next.deliverAt); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-t.doneC:
+				timer.Stop()
+				return
+			}
+		}
+
+		select {
+		case t.sinks[int(l.dest)] <- SourceMsg{
+			Source: l.source,
+			Msg:    next.msg,
+		}:
+		case <-t.doneC:
+			return
+		}
+
+		l.mutex.Lock()
+		l.queue[0] = pendingMsg{}
+		l.queue = l.queue[1:]
+		l.delivered++
+		l.mutex.Unlock()
+	}
+}
+
+// Link is a mirbft.Link which sends from a single node via the Transport.
+type Link struct {
+	Source    uint64
+	Transport *Transport
+}
+
+// Send queues the message for delivery to the destination, it never blocks.
+func (l *Link) Send(dest uint64, msg *msgs.Msg) {
+	l.Transport.Send(l.Source, dest, msg)
+}
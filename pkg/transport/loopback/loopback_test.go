@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package loopback_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/transport/loopback"
+)
+
+func suspectMsg(epoch uint64) *msgs.Msg {
+	return &msgs.Msg{
+		Type: &msgs.Msg_Suspect{
+			Suspect: &msgs.Suspect{
+				Epoch: epoch,
+			},
+		},
+	}
+}
+
+var _ = Describe("Transport", func() {
+	var (
+		transport *loopback.Transport
+	)
+
+	BeforeEach(func() {
+		transport = loopback.New(3, 0)
+		transport.Logger = mirbft.ConsoleErrorLogger
+		transport.Start()
+	})
+
+	AfterEach(func() {
+		transport.Stop()
+	})
+
+	receiveEpoch := func(dest uint64) uint64 {
+		var sourceMsg loopback.SourceMsg
+		Eventually(transport.RecvC(dest)).Should(Receive(&sourceMsg))
+		return sourceMsg.Msg.Type.(*msgs.Msg_Suspect).Suspect.Epoch
+	}
+
+	It("delivers every message in order without a receiver keeping up", func() {
+		var link mirbft.Link = transport.Link(0)
+		for i := uint64(0); i < 20000; i++ {
+			link.Send(1, suspectMsg(i))
+		}
+
+		Expect(transport.Stats(0, 1).Pending).To(BeNumerically(">", 10000))
+
+		for i := uint64(0); i < 20000; i++ {
+			sourceMsg := <-transport.RecvC(1)
+			Expect(sourceMsg.Source).To(Equal(uint64(0)))
+			Expect(sourceMsg.Msg.Type.(*msgs.Msg_Suspect).Suspect.Epoch).To(Equal(i))
+		}
+
+		Eventually(func() loopback.LinkStats { return transport.Stats(0, 1) }).Should(Equal(loopback.LinkStats{
+			Delivered: 20000,
+		}))
+	})
+
+	It("delays messages by the injected latency", func() {
+		transport.SetLinkLatency(0, 1, 200*time.Millisecond)
+		transport.Send(0, 1, suspectMsg(1))
+		transport.Send(0, 2, suspectMsg(2))
+
+		Expect(receiveEpoch(2)).To(Equal(uint64(2)))
+		Consistently(transport.RecvC(1), 100*time.Millisecond).ShouldNot(Receive())
+		Expect(receiveEpoch(1)).To(Equal(uint64(1)))
+	})
+
+	It("drops and counts messages when loss is injected", func() {
+		transport.SetLoss(1)
+		transport.Send(0, 1, suspectMsg(1))
+		Expect(transport.Stats(0, 1)).To(Equal(loopback.LinkStats{
+			Dropped: 1,
+		}))
+
+		transport.SetLoss(0)
+		transport.Send(0, 1, suspectMsg(2))
+		Expect(receiveEpoch(1)).To(Equal(uint64(2)))
+	})
+
+	It("drops messages between partitions until healed", func() {
+		transport.Partition([]uint64{0, 1})
+		transport.Send(0, 2, suspectMsg(1))
+		transport.Send(2, 1, suspectMsg(2))
+		transport.Send(1, 0, suspectMsg(3))
+
+		Expect(receiveEpoch(0)).To(Equal(uint64(3)))
+		Expect(transport.Stats(0, 2).Dropped).To(Equal(uint64(1)))
+		Expect(transport.Stats(2, 1).Dropped).To(Equal(uint64(1)))
+
+		transport.Heal()
+		transport.Send(0, 2, suspectMsg(4))
+		Expect(receiveEpoch(2)).To(Equal(uint64(4)))
+	})
+})
@@ -0,0 +1,407 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+)
+
+// MsgClass determines the priority of a message in a QueuedLink, and what
+// is discarded when its queue is full.
+type MsgClass int
+
+const (
+	// ClassControl messages drive epoch changes and garbage collection.  They
+	// are small and infrequent, but the network cannot recover from a faulty
+	// leader without them, so they are always sent first.  When the queue is
+	// full, a queued message which the new message supersedes, one of the
+	// same type for the same epoch, or for checkpoints the same sequence, is
+	// evicted.  If there is none, the new message is dropped, so that a flood
+	// of one kind of control message cannot evict all others.
+	ClassControl MsgClass = iota
+
+	// ClassAgreement messages are the three phase commit and request
+	// acknowledgement traffic.  When the queue is full, the oldest agreement
+	// message is evicted.  A destination whose queue fills is lagging, and
+	// will recover the sequences it misses through checkpoints and state
+	// transfer, whereas dropping new messages would leave it missing the
+	// current sequences even once it has drained its queue.
+	ClassAgreement
+
+	// ClassBulk messages carry request and batch data to replicas which are
+	// missing it.  They are potentially large, and are sent only when there
+	// is no other traffic.  When the queue is full, new messages are dropped.
+	ClassBulk
+
+	numMsgClasses
+)
+
+// Classify returns the class of the message.
+func Classify(msg *msgs.Msg) MsgClass {
+	switch msg.Type.(type) {
+	case *msgs.Msg_Checkpoint,
+		*msgs.Msg_Suspect,
+		*msgs.Msg_EpochChange,
+		*msgs.Msg_EpochChangeAck,
+		*msgs.Msg_NewEpoch,
+		*msgs.Msg_NewEpochEcho,
+		*msgs.Msg_NewEpochReady:
+		return ClassControl
+	case *msgs.Msg_ForwardBatch,
+		*msgs.Msg_ForwardRequest:
+		return ClassBulk
+	default:
+		return ClassAgreement
+	}
+}
+
+const (
+	// DefaultControlQueueSize, DefaultAgreementQueueSize, and
+	// DefaultBulkQueueSize are the number of messages of each class
+	// buffered for each destination when the QueuedLink sizes are not set.
+	DefaultControlQueueSize   = 100
+	DefaultAgreementQueueSize = 1000
+	DefaultBulkQueueSize      = 100
+)
+
+// controlKey identifies a control message by its type and the epoch or
+// sequence it refers to.  A control message supersedes any queued control
+// message with the same key.
+type controlKey struct {
+	msgType string
+	source  uint64
+	number  uint64
+}
+
+func newControlKey(msg *msgs.Msg) controlKey {
+	key := controlKey{
+		msgType: msgType(msg),
+	}
+
+	switch innerMsg := msg.Type.(type) {
+	case *msgs.Msg_Checkpoint:
+		key.number = innerMsg.Checkpoint.SeqNo
+	case *msgs.Msg_Suspect:
+		key.number = innerMsg.Suspect.Epoch
+	case *msgs.Msg_EpochChange:
+		key.number = innerMsg.EpochChange.NewEpoch
+	case *msgs.Msg_EpochChangeAck:
+		// Acks for the epoch changes of different originators are distinct.
+		key.source = innerMsg.EpochChangeAck.Originator
+		key.number = innerMsg.EpochChangeAck.GetEpochChange().GetNewEpoch()
+	case *msgs.Msg_NewEpoch:
+		key.number = innerMsg.NewEpoch.GetNewConfig().GetConfig().GetNumber()
+	case *msgs.Msg_NewEpochEcho:
+		key.number = innerMsg.NewEpochEcho.GetConfig().GetNumber()
+	case *msgs.Msg_NewEpochReady:
+		key.number = innerMsg.NewEpochReady.GetConfig().GetNumber()
+	}
+
+	return key
+}
+
+// DropWarnInterval is the minimum interval between the warnings a
+// QueuedLink logs for the messages dropped for a single destination.
+const DropWarnInterval = time.Second
+
+// QueueStats reports the state of the queue for a single destination.
+type QueueStats struct {
+	// Depth is the number of messages currently queued.
+	Depth int
+
+	// Dropped is the number of messages which have been dropped or evicted
+	// because the queue for their class was full.
+	Dropped uint64
+
+	// ClassDropped is the number of messages of each class, indexed by
+	// MsgClass, which have been dropped or evicted.
+	ClassDropped [numMsgClasses]uint64
+}
+
+// QueuedLink is a mirbft.Link which wraps another, potentially blocking,
+// mirbft.Link.  Messages are queued per destination, and each destination
+// is sent to by its own go routine, so that a slow peer does not delay the
+// messages to any other.  Each destination has a bounded queue per message
+// class, and queued messages are sent in class order, so that control
+// messages are never stuck behind bulk data.  Note that if the wrapped link
+// itself buffers messages, those messages are sent in the order they were
+// buffered, so the wrapped link should buffer as little as possible.
+//
+// Send never blocks.  Messages which do not fit in their queue are discarded
+// as described by their MsgClass.  Note that the state machine only
+// retransmits epoch change traffic and request acks.  A lost Preprepare,
+// Prepare, or Commit is recovered only once the replicas suspect the leader
+// and change epochs, so the queue sizes should be large enough that
+// agreement messages are discarded only for a peer which is far behind.
+// The dropped counts of each class are reported by Stats.
+type QueuedLink struct {
+	// Link is the underlying link which messages are sent through.
+	Link mirbft.Link
+
+	// Logger, if set, is used to report dropped messages.  If not set,
+	// mirbft.ConsoleWarnLogger is used.
+	Logger mirbft.Logger
+
+	// ControlQueueSize, AgreementQueueSize, and BulkQueueSize are the number
+	// of messages of each class buffered for each destination.  They default
+	// to DefaultControlQueueSize, DefaultAgreementQueueSize, and
+	// DefaultBulkQueueSize respectively.
+	ControlQueueSize   int
+	AgreementQueueSize int
+	BulkQueueSize      int
+
+	mutex  sync.Mutex
+	queues map[uint64]*destQueue
+	doneC  chan struct{}
+	wg     sync.WaitGroup
+}
+
+type destQueue struct {
+	mutex   sync.Mutex
+	dest    uint64
+	classes [numMsgClasses][]*msgs.Msg
+	limits  [numMsgClasses]int
+	dropped [numMsgClasses]uint64
+	readyC  chan struct{}
+
+	// lastWarn is when a dropped message was last logged, and unwarned
+	// is the number of messages dropped since.
+	lastWarn time.Time
+	unwarned uint64
+}
+
+// Start prepares the link to send, it must be called before Send.
+func (ql *QueuedLink) Start() {
+	ql.mutex.Lock()
+	defer ql.mutex.Unlock()
+
+	if ql.doneC != nil {
+		return
+	}
+
+	if ql.Logger == nil {
+		ql.Logger = mirbft.ConsoleWarnLogger
+	}
+
+	if ql.ControlQueueSize == 0 {
+		ql.ControlQueueSize = DefaultControlQueueSize
+	}
+
+	if ql.AgreementQueueSize == 0 {
+		ql.AgreementQueueSize = DefaultAgreementQueueSize
+	}
+
+	if ql.BulkQueueSize == 0 {
+		ql.BulkQueueSize = DefaultBulkQueueSize
+	}
+
+	ql.queues = map[uint64]*destQueue{}
+	ql.doneC = make(chan struct{})
+}
+
+// Stop discards any queued messages, and waits for the go routines sending
+// to each destination to exit.  It does not stop the underlying link, and
+// a send already in progress on the underlying link must complete before
+// Stop returns.
+func (ql *QueuedLink) Stop() {
+	ql.mutex.Lock()
+	if ql.doneC == nil {
+		ql.mutex.Unlock()
+		return
+	}
+
+	select {
+	case <-ql.doneC:
+	default:
+		close(ql.doneC)
+	}
+	ql.mutex.Unlock()
+
+	ql.wg.Wait()
+}
+
+// Send enqueues the message for transmission to the destination, and never
+// blocks.  If the queue for the message's class is full, a queued message
+// is evicted or this message is dropped, as described by its MsgClass.
+// Drops are logged at most once per DropWarnInterval for each destination.
+func (ql *QueuedLink) Send(dest uint64, msg *msgs.Msg) {
+	dq := ql.destQueue(dest)
+	if dq == nil {
+		return
+	}
+
+	class := Classify(msg)
+
+	dq.mutex.Lock()
+	queue := dq.classes[class]
+	full := len(queue) >= dq.limits[class]
+	enqueued := true
+	switch {
+	case !full:
+		dq.classes[class] = append(queue, msg)
+	case class == ClassAgreement:
+		queue[0] = nil
+		dq.classes[class] = append(queue[1:], msg)
+	case class == ClassControl:
+		enqueued = dq.replaceControl(msg)
+	default:
+		enqueued = false
+	}
+
+	var warnDropped uint64
+	if full {
+		dq.dropped[class]++
+		dq.unwarned++
+		if now := time.Now(); now.Sub(dq.lastWarn) >= DropWarnInterval {
+			warnDropped = dq.unwarned
+			dq.unwarned = 0
+			dq.lastWarn = now
+		}
+	}
+	dq.mutex.Unlock()
+
+	if warnDropped > 0 {
+		ql.Logger.Log(mirbft.LevelWarn, "dropping messages, peer queue full", "dest", dest, "dropped", warnDropped, "class", class, "type", msgType(msg))
+	}
+
+	if !enqueued {
+		return
+	}
+
+	select {
+	case dq.readyC <- struct{}{}:
+	default:
+	}
+}
+
+// Stats returns the queue depth and number of dropped messages for
+// each destination which has been sent to.
+func (ql *QueuedLink) Stats() map[uint64]QueueStats {
+	ql.mutex.Lock()
+	defer ql.mutex.Unlock()
+
+	result := make(map[uint64]QueueStats, len(ql.queues))
+	for dest, dq := range ql.queues {
+		dq.mutex.Lock()
+		stats := QueueStats{
+			ClassDropped: dq.dropped,
+		}
+		for class, queue := range dq.classes {
+			stats.Depth += len(queue)
+			stats.Dropped += dq.dropped[class]
+		}
+		result[dest] = stats
+		dq.mutex.Unlock()
+	}
+
+	return result
+}
+
+// destQueue returns the queue for the destination, creating it and starting
+// its sending go routine if required.  It returns nil if the link is stopped.
+func (ql *QueuedLink) destQueue(dest uint64) *destQueue {
+	ql.mutex.Lock()
+	defer ql.mutex.Unlock()
+
+	if ql.doneC == nil {
+		panic("QueuedLink must be started before sending")
+	}
+
+	select {
+	case <-ql.doneC:
+		return nil
+	default:
+	}
+
+	dq, ok := ql.queues[dest]
+	if ok {
+		return dq
+	}
+
+	dq = &destQueue{
+		dest: dest,
+		limits: [numMsgClasses]int{
+			ClassControl:   ql.ControlQueueSize,
+			ClassAgreement: ql.AgreementQueueSize,
+			ClassBulk:      ql.BulkQueueSize,
+		},
+		readyC: make(chan struct{}, 1),
+	}
+	ql.queues[dest] = dq
+
+	ql.wg.Add(1)
+	go ql.run(dq)
+
+	return dq
+}
+
+func (ql *QueuedLink) run(dq *destQueue) {
+	defer ql.wg.Done()
+	for {
+		msg := dq.pop()
+		if msg == nil {
+			select {
+			case <-dq.readyC:
+				continue
+			case <-ql.doneC:
+				return
+			}
+		}
+
+		select {
+		case <-ql.doneC:
+			return
+		default:
+		}
+
+		ql.Link.Send(dq.dest, msg)
+	}
+}
+
+// replaceControl evicts the oldest queued control message which the given
+// message supersedes, and enqueues the message in its place.  It returns
+// false, without enqueuing the message, if no queued message is superseded.
+// The caller must hold the queue mutex.
+func (dq *destQueue) replaceControl(msg *msgs.Msg) bool {
+	key := newControlKey(msg)
+	queue := dq.classes[ClassControl]
+	for i, queued := range queue {
+		if newControlKey(queued) != key {
+			continue
+		}
+
+		copy(queue[i:], queue[i+1:])
+		queue[len(queue)-1] = msg
+		return true
+	}
+
+	return false
+}
+
+// pop removes and returns the next message to send, in class order,
+// or nil if no messages are queued.
+func (dq *destQueue) pop() *msgs.Msg {
+	dq.mutex.Lock()
+	defer dq.mutex.Unlock()
+
+	for class, queue := range dq.classes {
+		if len(queue) == 0 {
+			continue
+		}
+
+		msg := queue[0]
+		queue[0] = nil
+		dq.classes[class] = queue[1:]
+		return msg
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/transport"
+)
+
+type DestMsg struct {
+	Dest uint64
+	Msg  *msgs.Msg
+}
+
+// GatedLink blocks each send to a destination until it is released.
+type GatedLink struct {
+	SentC  chan DestMsg
+	GatesC map[uint64]chan struct{}
+}
+
+func (gl *GatedLink) Send(dest uint64, msg *msgs.Msg) {
+	<-gl.GatesC[dest]
+	gl.SentC <- DestMsg{
+		Dest: dest,
+		Msg:  msg,
+	}
+}
+
+func commitMsg(seqNo uint64) *msgs.Msg {
+	return &msgs.Msg{
+		Type: &msgs.Msg_Commit{
+			Commit: &msgs.Commit{
+				SeqNo: seqNo,
+			},
+		},
+	}
+}
+
+func checkpointMsg(seqNo uint64, value string) *msgs.Msg {
+	return &msgs.Msg{
+		Type: &msgs.Msg_Checkpoint{
+			Checkpoint: &msgs.Checkpoint{
+				SeqNo: seqNo,
+				Value: []byte(value),
+			},
+		},
+	}
+}
+
+func forwardBatchMsg(seqNo uint64) *msgs.Msg {
+	return &msgs.Msg{
+		Type: &msgs.Msg_ForwardBatch{
+			ForwardBatch: &msgs.ForwardBatch{
+				SeqNo: seqNo,
+			},
+		},
+	}
+}
+
+var _ = Describe("QueuedLink", func() {
+	var (
+		gatedLink  *GatedLink
+		logger     *RecordingLogger
+		queuedLink *transport.QueuedLink
+	)
+
+	sendAll := func(dest uint64, count int) []*msgs.Msg {
+		for i := 0; i < count; i++ {
+			gatedLink.GatesC[dest] <- struct{}{}
+		}
+
+		var sent []*msgs.Msg
+		for i := 0; i < count; i++ {
+			var destMsg DestMsg
+			Eventually(gatedLink.SentC).Should(Receive(&destMsg))
+			sent = append(sent, destMsg.Msg)
+		}
+
+		return sent
+	}
+
+	BeforeEach(func() {
+		gatedLink = &GatedLink{
+			SentC: make(chan DestMsg, 100),
+			GatesC: map[uint64]chan struct{}{
+				1: make(chan struct{}, 100),
+				2: make(chan struct{}, 100),
+			},
+		}
+
+		logger = &RecordingLogger{}
+
+		queuedLink = &transport.QueuedLink{
+			Link:               gatedLink,
+			Logger:             logger,
+			ControlQueueSize:   2,
+			AgreementQueueSize: 2,
+			BulkQueueSize:      2,
+		}
+		queuedLink.Start()
+	})
+
+	AfterEach(func() {
+		for _, gateC := range gatedLink.GatesC {
+			close(gateC)
+		}
+		queuedLink.Stop()
+	})
+
+	It("does not delay other destinations behind a blocked one", func() {
+		queuedLink.Send(1, commitMsg(1))
+		queuedLink.Send(2, commitMsg(2))
+
+		gatedLink.GatesC[2] <- struct{}{}
+		Eventually(gatedLink.SentC).Should(Receive(Equal(DestMsg{
+			Dest: 2,
+			Msg:  commitMsg(2),
+		})))
+		Consistently(gatedLink.SentC).ShouldNot(Receive())
+	})
+
+	It("sends control messages ahead of other queued messages", func() {
+		queuedLink.Send(1, forwardBatchMsg(1))
+		// Wait until the first message is blocked in the link
+		Eventually(func() int { return queuedLink.Stats()[1].Depth }).Should(Equal(0))
+
+		queuedLink.Send(1, forwardBatchMsg(2))
+		queuedLink.Send(1, commitMsg(3))
+		queuedLink.Send(1, suspectMsg(4))
+
+		Expect(sendAll(1, 4)).To(Equal([]*msgs.Msg{
+			forwardBatchMsg(1),
+			suspectMsg(4),
+			commitMsg(3),
+			forwardBatchMsg(2),
+		}))
+	})
+
+	It("drops and evicts messages when a queue is full", func() {
+		queuedLink.Send(1, commitMsg(1))
+		Eventually(func() int { return queuedLink.Stats()[1].Depth }).Should(Equal(0))
+
+		queuedLink.Send(1, commitMsg(2))
+		queuedLink.Send(1, commitMsg(3))
+		queuedLink.Send(1, commitMsg(4))
+		queuedLink.Send(1, suspectMsg(5))
+		queuedLink.Send(1, suspectMsg(6))
+		queuedLink.Send(1, suspectMsg(7))
+		queuedLink.Send(1, forwardBatchMsg(8))
+		queuedLink.Send(1, forwardBatchMsg(9))
+		queuedLink.Send(1, forwardBatchMsg(10))
+
+		var classDropped [3]uint64
+		classDropped[transport.ClassControl] = 1
+		classDropped[transport.ClassAgreement] = 1
+		classDropped[transport.ClassBulk] = 1
+		Expect(queuedLink.Stats()).To(Equal(map[uint64]transport.QueueStats{
+			1: {
+				Depth:        6,
+				Dropped:      3,
+				ClassDropped: classDropped,
+			},
+		}))
+
+		Expect(sendAll(1, 7)).To(Equal([]*msgs.Msg{
+			commitMsg(1),
+			suspectMsg(5),
+			suspectMsg(6),
+			commitMsg(3),
+			commitMsg(4),
+			forwardBatchMsg(8),
+			forwardBatchMsg(9),
+		}))
+	})
+
+	It("evicts only the control messages superseded by a new one", func() {
+		queuedLink.Send(1, commitMsg(1))
+		Eventually(func() int { return queuedLink.Stats()[1].Depth }).Should(Equal(0))
+
+		queuedLink.Send(1, checkpointMsg(10, "a"))
+		queuedLink.Send(1, suspectMsg(2))
+		queuedLink.Send(1, checkpointMsg(10, "b"))
+		queuedLink.Send(1, checkpointMsg(20, "c"))
+		queuedLink.Send(1, suspectMsg(3))
+
+		Expect(queuedLink.Stats()[1].ClassDropped[transport.ClassControl]).To(Equal(uint64(3)))
+		Expect(sendAll(1, 3)).To(Equal([]*msgs.Msg{
+			commitMsg(1),
+			suspectMsg(2),
+			checkpointMsg(10, "b"),
+		}))
+	})
+
+	It("rate limits the warnings for dropped messages", func() {
+		queuedLink.Send(1, commitMsg(1))
+		Eventually(func() int { return queuedLink.Stats()[1].Depth }).Should(Equal(0))
+
+		for i := uint64(2); i < 100; i++ {
+			queuedLink.Send(1, forwardBatchMsg(i))
+		}
+
+		Expect(queuedLink.Stats()[1].Dropped).To(Equal(uint64(96)))
+		Expect(logger.Entries()).To(Equal([]string{"dropping messages, peer queue full"}))
+	})
+})
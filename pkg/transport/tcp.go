@@ -0,0 +1,631 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package transport provides network implementations of the mirbft.Link
+// interface, along with the ingress path which delivers the messages received
+// from other replicas to the local mirbft.Node.
+package transport
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+)
+
+const (
+	// DefaultQueueSize is the number of messages buffered for each peer
+	// when TCP.QueueSize is not set.
+	DefaultQueueSize = 1000
+
+	// DefaultMaxMsgSize is the largest frame accepted from a peer
+	// when TCP.MaxMsgSize is not set.
+	DefaultMaxMsgSize = 64 * 1024 * 1024
+
+	// DefaultMinBackoff and DefaultMaxBackoff bound the delay between
+	// attempts to reconnect to a peer when TCP.MinBackoff and
+	// TCP.MaxBackoff are not set.
+	DefaultMinBackoff = 50 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// Stepper is the subset of *mirbft.Node used by the ingress path.  Step must
+// validate the message before stepping it into the state machine, returning a
+// *mirbft.ValidationError for malformed messages.
+type Stepper interface {
+	Step(source uint64, msg *msgs.Msg) error
+}
+
+// ForwardRequestHandler is the subset of *mirbft.ClientProcessor used by the
+// ingress path.  Forwarded requests must not be stepped into the state machine.
+type ForwardRequestHandler interface {
+	StepForwardRequest(source uint64, fr *msgs.ForwardRequest) error
+}
+
+// TCP is a mirbft.Link which maintains a TCP connection to each peer.  Messages
+// are sent as protobuf encoded msgs.Msg frames, each prefixed by its varint
+// encoded length.  Upon connecting, the dialing node first sends its node ID
+// as a uvarint.  Note that with plain TCP, this claimed node ID is trusted as
+// the source of every message on the connection, so unless Certificates is
+// set, this transport should only be used on networks where peers cannot be
+// impersonated.
+//
+// When Certificates is set, connections are secured with mutually authenticated
+// TLS.  Each peer certificate must chain to a trusted CA and map (via
+// NodeIdentity) to the ID of a member of the network, and connections whose
+// claimed node ID does not match the authenticated node are rejected, so a
+// faulty node cannot inject messages on behalf of another.
+//
+// The members of the network are initially the nodes of Peers.  As the network
+// is reconfigured, SetMembers should be invoked with the nodes of each new
+// network configuration, so that nodes which have been removed can no longer
+// connect, nor deliver messages over connections which are already open.
+//
+// Send never blocks.  Each peer has a bounded queue of outbound messages, and if
+// the queue is full, or the connection fails while sending, messages are dropped.
+// Note that the state machine only retransmits epoch change traffic and request
+// acks, a lost Preprepare, Prepare, or Commit is recovered only through an epoch
+// change once the replicas suspect the leader.
+type TCP struct {
+	// NodeID is the ID of this node.
+	NodeID uint64
+
+	// Peers maps the ID of each other node to the address it listens on.
+	Peers map[uint64]string
+
+	// Node receives the messages sent by peers.
+	Node Stepper
+
+	// ClientProcessor, if set, receives the forwarded requests sent by peers.
+	// If not set, forwarded requests are discarded.
+	ClientProcessor ForwardRequestHandler
+
+	// Logger, if set, is used to report connection failures and dropped
+	// messages.  If not set, mirbft.ConsoleWarnLogger is used.
+	Logger mirbft.Logger
+
+	// QueueSize is the number of outbound messages which may be buffered
+	// for each peer.  Defaults to DefaultQueueSize.
+	QueueSize int
+
+	// MaxMsgSize is the largest frame which will be accepted from a peer.
+	// Defaults to DefaultMaxMsgSize.
+	MaxMsgSize int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff between
+	// attempts to reconnect to a peer.  Default to DefaultMinBackoff and
+	// DefaultMaxBackoff respectively.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Certificates, if set, enables TLS using the certificates of the store.
+	// The store must already have been loaded, and may be reloaded while
+	// the transport is running.
+	Certificates *CertificateStore
+
+	// NodeIdentity maps an authenticated peer certificate to the ID of the
+	// node it belongs to.  Defaults to CommonNameIdentity.
+	NodeIdentity func(*x509.Certificate) (uint64, error)
+
+	mutex    sync.Mutex
+	listener net.Listener
+	peers    map[uint64]*tcpPeer
+	members  map[uint64]struct{}
+	conns    map[net.Conn]struct{}
+	doneC    chan struct{}
+	wg       sync.WaitGroup
+}
+
+type tcpPeer struct {
+	id      uint64
+	address string
+	queueC  chan frame
+}
+
+// frame is a message queued for a peer.  Messages supplied via Broadcast
+// are already encoded, and the encoded bytes are shared by each peer,
+// while messages supplied via Send are encoded as they are written.
+type frame struct {
+	msg      *msgs.Msg
+	msgBytes []byte
+}
+
+// Start begins accepting connections from peers on the given listener, and
+// begins connecting to each of the peers.  The listener is closed on Stop.
+func (t *TCP) Start(listener net.Listener) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.doneC != nil {
+		return errors.New("transport already started")
+	}
+
+	if t.Node == nil {
+		return errors.New("transport requires a Node for ingress")
+	}
+
+	if t.QueueSize == 0 {
+		t.QueueSize = DefaultQueueSize
+	}
+
+	if t.MaxMsgSize == 0 {
+		t.MaxMsgSize = DefaultMaxMsgSize
+	}
+
+	if t.MinBackoff == 0 {
+		t.MinBackoff = DefaultMinBackoff
+	}
+
+	if t.MaxBackoff == 0 {
+		t.MaxBackoff = DefaultMaxBackoff
+	}
+
+	if t.Certificates != nil {
+		if _, err := t.Certificates.getCertificate(); err != nil {
+			return err
+		}
+
+		if t.NodeIdentity == nil {
+			t.NodeIdentity = CommonNameIdentity
+		}
+	}
+
+	t.listener = listener
+	t.doneC = make(chan struct{})
+	t.conns = map[net.Conn]struct{}{}
+	t.peers = map[uint64]*tcpPeer{}
+
+	for id, address := range t.Peers {
+		if id == t.NodeID {
+			continue
+		}
+
+		peer := &tcpPeer{
+			id:      id,
+			address: address,
+			queueC:  make(chan frame, t.QueueSize),
+		}
+		t.peers[id] = peer
+
+		t.wg.Add(1)
+		go t.runPeer(peer)
+	}
+
+	t.wg.Add(1)
+	go t.accept()
+
+	return nil
+}
+
+// Stop closes the listener and all connections, and waits for
+// the transport's go routines to exit.
+func (t *TCP) Stop() {
+	t.mutex.Lock()
+	if t.doneC == nil {
+		t.mutex.Unlock()
+		return
+	}
+
+	select {
+	case <-t.doneC:
+	default:
+		close(t.doneC)
+		t.listener.Close()
+		for conn := range t.conns {
+			conn.Close()
+		}
+	}
+	t.mutex.Unlock()
+
+	t.wg.Wait()
+}
+
+// SetMembers sets the nodes of the current network configuration, for
+// instance, the Nodes of the network config supplied to App.Snap or returned
+// from App.TransferTo.  Connections and messages from nodes which are not
+// members are rejected.
+func (t *TCP) SetMembers(nodes []uint64) {
+	members := make(map[uint64]struct{}, len(nodes))
+	for _, id := range nodes {
+		members[id] = struct{}{}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.members = members
+}
+
+// isMember returns whether the node is a member of the network other
+// than this node.  Until SetMembers is invoked, the members are the
+// nodes of Peers.
+func (t *TCP) isMember(id uint64) bool {
+	if id == t.NodeID {
+		return false
+	}
+
+	t.mutex.Lock()
+	members := t.members
+	t.mutex.Unlock()
+
+	if members == nil {
+		_, ok := t.Peers[id]
+		return ok
+	}
+
+	_, ok := members[id]
+	return ok
+}
+
+// Send enqueues the message for transmission to the destination, and
+// never blocks.  If the destination's queue is full, the message is dropped.
+func (t *TCP) Send(dest uint64, msg *msgs.Msg) {
+	peer, ok := t.peers[dest]
+	if !ok {
+		t.logger().Log(mirbft.LevelWarn, "dropping message for unknown peer", "dest", dest)
+		return
+	}
+
+	select {
+	case peer.queueC <- frame{msg: msg}:
+	default:
+		t.logger().Log(mirbft.LevelWarn, "dropping message, peer queue full", "dest", dest, "type", msgType(msg))
+	}
+}
+
+// Broadcast enqueues the encoded message for transmission to each of the
+// destinations, and never blocks.  The encoded bytes are shared between the
+// destinations, and must not be modified.  As with Send, if a destination's
+// queue is full, the message is dropped for that destination.
+func (t *TCP) Broadcast(dests []uint64, msgBytes []byte) {
+	for _, dest := range dests {
+		peer, ok := t.peers[dest]
+		if !ok {
+			t.logger().Log(mirbft.LevelWarn, "dropping message for unknown peer", "dest", dest)
+			continue
+		}
+
+		select {
+		case peer.queueC <- frame{msgBytes: msgBytes}:
+		default:
+			t.logger().Log(mirbft.LevelWarn, "dropping message, peer queue full", "dest", dest)
+		}
+	}
+}
+
+func (t *TCP) logger() mirbft.Logger {
+	if t.Logger == nil {
+		return mirbft.ConsoleWarnLogger
+	}
+	return t.Logger
+}
+
+// trackConn registers the connection to be closed on Stop, it returns
+// false if the transport is already stopping.
+func (t *TCP) trackConn(conn net.Conn) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	select {
+	case <-t.doneC:
+		return false
+	default:
+	}
+	t.conns[conn] = struct{}{}
+	return true
+}
+
+func (t *TCP) untrackConn(conn net.Conn) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.conns, conn)
+	conn.Close()
+}
+
+func (t *TCP) runPeer(peer *tcpPeer) {
+	defer t.wg.Done()
+
+	backoff := t.MinBackoff
+	for {
+		conn, err := t.dial(peer)
+		if err == nil {
+			backoff = t.MinBackoff
+			err = t.sendTo(peer, conn)
+		}
+
+		select {
+		case <-t.doneC:
+			return
+		default:
+		}
+
+		t.logger().Log(mirbft.LevelDebug, "connection to peer failed, will retry", "dest", peer.id, "address", peer.address, "backoff", backoff, "error", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-t.doneC:
+			return
+		}
+
+		backoff *= 2
+		if backoff > t.MaxBackoff {
+			backoff = t.MaxBackoff
+		}
+	}
+}
+
+// dial connects to the peer, and when TLS is enabled, completes
+// the handshake, authenticating the peer.
+func (t *TCP) dial(peer *tcpPeer) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", peer.address, t.MaxBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Certificates == nil {
+		return conn, nil
+	}
+
+	tlsConn := tls.Client(conn, t.clientTLSConfig(peer.id))
+	tlsConn.SetDeadline(time.Now().Add(t.MaxBackoff))
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, errors.WithMessage(err, "TLS handshake failed")
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	return tlsConn, nil
+}
+
+// sendTo transmits the peer's queued messages over the connection until
+// the connection fails or the transport is stopped.
+func (t *TCP) sendTo(peer *tcpPeer, conn net.Conn) error {
+	if !t.trackConn(conn) {
+		conn.Close()
+		return nil
+	}
+	defer t.untrackConn(conn)
+
+	// We never expect the peer to write to us on this connection, but
+	// reading allows us to detect that the connection has closed while idle.
+	closedC := make(chan struct{})
+	go func() {
+		io.Copy(ioutil.Discard, conn)
+		close(closedC)
+	}()
+
+	writer := bufio.NewWriter(conn)
+	if err := writeNodeID(writer, t.NodeID); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return errors.WithMessage(err, "could not send node ID")
+	}
+
+	for {
+		select {
+		case f := <-peer.queueC:
+			if err := f.write(writer); err != nil {
+				return err
+			}
+
+			// Write any other pending messages before flushing
+			for pending := len(peer.queueC); pending > 0; pending-- {
+				f := <-peer.queueC
+				if err := f.write(writer); err != nil {
+					return err
+				}
+			}
+
+			if err := writer.Flush(); err != nil {
+				return errors.WithMessage(err, "could not flush messages")
+			}
+		case <-closedC:
+			return errors.New("connection closed by peer")
+		case <-t.doneC:
+			return nil
+		}
+	}
+}
+
+func (t *TCP) accept() {
+	defer t.wg.Done()
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			select {
+			case <-t.doneC:
+			default:
+				t.logger().Log(mirbft.LevelError, "listener failed, no longer accepting connections", "error", err)
+			}
+			return
+		}
+
+		if !t.trackConn(conn) {
+			conn.Close()
+			return
+		}
+
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			defer t.untrackConn(conn)
+			err := t.receiveFrom(conn)
+			t.logger().Log(mirbft.LevelDebug, "closing inbound connection", "remote", conn.RemoteAddr(), "error", err)
+		}()
+	}
+}
+
+// receiveFrom reads the node ID of the dialing peer, and then delivers each
+// message it sends until the connection fails or the node stops.  When TLS
+// is enabled, the claimed node ID must match the authenticated peer.  The
+// node ID must be received within MaxBackoff.
+func (t *TCP) receiveFrom(conn net.Conn) error {
+	authenticated, conn, err := t.handshake(conn)
+	if err != nil {
+		return err
+	}
+
+	// A peer which connects but never identifies itself must not
+	// hold the connection open indefinitely.
+	conn.SetReadDeadline(time.Now().Add(t.MaxBackoff))
+	reader := bufio.NewReader(conn)
+	source, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return errors.WithMessage(err, "could not read node ID")
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	if !t.isMember(source) {
+		return errors.Errorf("connection from unknown node %d", source)
+	}
+
+	if t.Certificates != nil && source != authenticated {
+		return errors.Errorf("connection claims to be from node %d but authenticated as node %d", source, authenticated)
+	}
+
+	for {
+		msg, err := readFrame(reader, t.MaxMsgSize)
+		if err != nil {
+			return err
+		}
+
+		if !t.isMember(source) {
+			return errors.Errorf("node %d is no longer a member of the network", source)
+		}
+
+		if err := t.deliver(source, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handshake completes the TLS handshake for an inbound connection, if TLS is
+// enabled, returning the ID of the authenticated peer and the secured connection.
+func (t *TCP) handshake(conn net.Conn) (uint64, net.Conn, error) {
+	if t.Certificates == nil {
+		return 0, conn, nil
+	}
+
+	tlsConn := tls.Server(conn, t.serverTLSConfig())
+	tlsConn.SetDeadline(time.Now().Add(t.MaxBackoff))
+	if err := tlsConn.Handshake(); err != nil {
+		return 0, nil, errors.WithMessage(err, "TLS handshake failed")
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	// The chain was verified during the handshake, but we must still
+	// map the peer certificate to the node it authenticates.
+	id, err := t.NodeIdentity(tlsConn.ConnectionState().PeerCertificates[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return id, tlsConn, nil
+}
+
+// deliver routes a message received from a peer to the client processor,
+// if it is a forwarded request, or otherwise to the node.
+func (t *TCP) deliver(source uint64, msg *msgs.Msg) error {
+	if fr, ok := msg.Type.(*msgs.Msg_ForwardRequest); ok {
+		if t.ClientProcessor == nil {
+			return nil
+		}
+
+		if err := t.ClientProcessor.StepForwardRequest(source, fr.ForwardRequest); err != nil {
+			t.logger().Log(mirbft.LevelDebug, "rejected forwarded request", "source", source, "error", err)
+		}
+		return nil
+	}
+
+	err := t.Node.Step(source, msg)
+	if _, ok := err.(*mirbft.ValidationError); ok {
+		t.logger().Log(mirbft.LevelWarn, "discarding malformed message", "source", source, "type", msgType(msg), "error", err)
+		return nil
+	}
+
+	return err
+}
+
+func writeNodeID(dest io.Writer, id uint64) error {
+	idBuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(idBuf, id)
+	if _, err := dest.Write(idBuf[:n]); err != nil {
+		return errors.WithMessage(err, "could not write node ID")
+	}
+	return nil
+}
+
+func (f frame) write(dest io.Writer) error {
+	if f.msg != nil {
+		return writeFrame(dest, f.msg)
+	}
+
+	return writeFrameBytes(dest, f.msgBytes)
+}
+
+func writeFrame(dest io.Writer, msg *msgs.Msg) error {
+	msgBytes, err := proto.Marshal(msg)
+	if err != nil {
+		return errors.WithMessage(err, "could not marshal")
+	}
+
+	return writeFrameBytes(dest, msgBytes)
+}
+
+func writeFrameBytes(dest io.Writer, msgBytes []byte) error {
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutVarint(lenBuf, int64(len(msgBytes)))
+	if _, err := dest.Write(lenBuf[:n]); err != nil {
+		return errors.WithMessage(err, "could not write length prefix")
+	}
+
+	if _, err := dest.Write(msgBytes); err != nil {
+		return errors.WithMessage(err, "could not write message")
+	}
+
+	return nil
+}
+
+func readFrame(reader *bufio.Reader, maxSize int) (*msgs.Msg, error) {
+	l, err := binary.ReadVarint(reader)
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, errors.WithMessage(err, "could not read size prefix")
+	}
+
+	if l < 0 || l > int64(maxSize) {
+		return nil, errors.Errorf("frame size %d exceeds maximum of %d", l, maxSize)
+	}
+
+	buffer := make([]byte, l)
+	if _, err := io.ReadFull(reader, buffer); err != nil {
+		return nil, errors.WithMessage(err, "could not read message")
+	}
+
+	msg := &msgs.Msg{}
+	if err := proto.Unmarshal(buffer, msg); err != nil {
+		return nil, errors.WithMessage(err, "could not unmarshal message")
+	}
+
+	return msg, nil
+}
+
+func msgType(msg *msgs.Msg) string {
+	return fmt.Sprintf("%T", msg.Type)
+}
@@ -0,0 +1,276 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport_test
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/transport"
+)
+
+type SourceMsg struct {
+	Source uint64
+	Msg    *msgs.Msg
+}
+
+type FakeNode struct {
+	StepC chan SourceMsg
+}
+
+func (fn *FakeNode) Step(source uint64, msg *msgs.Msg) error {
+	if err := mirbft.ValidateMsg(msg, &mirbft.Config{}); err != nil {
+		return err
+	}
+
+	fn.StepC <- SourceMsg{
+		Source: source,
+		Msg:    msg,
+	}
+	return nil
+}
+
+type FakeClientProcessor struct {
+	ForwardC chan SourceMsg
+}
+
+func (fcp *FakeClientProcessor) StepForwardRequest(source uint64, fr *msgs.ForwardRequest) error {
+	fcp.ForwardC <- SourceMsg{
+		Source: source,
+		Msg: &msgs.Msg{
+			Type: &msgs.Msg_ForwardRequest{
+				ForwardRequest: fr,
+			},
+		},
+	}
+	return nil
+}
+
+// RecordingLogger records the text of each log entry.
+type RecordingLogger struct {
+	mutex   sync.Mutex
+	entries []string
+}
+
+func (rl *RecordingLogger) Log(level mirbft.LogLevel, text string, args ...interface{}) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.entries = append(rl.entries, text)
+}
+
+func (rl *RecordingLogger) Entries() []string {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	return append([]string(nil), rl.entries...)
+}
+
+func suspectMsg(epoch uint64) *msgs.Msg {
+	return &msgs.Msg{
+		Type: &msgs.Msg_Suspect{
+			Suspect: &msgs.Suspect{
+				Epoch: epoch,
+			},
+		},
+	}
+}
+
+var _ = Describe("TCP", func() {
+	var (
+		listeners  []net.Listener
+		peers      map[uint64]string
+		nodes      []*FakeNode
+		processors []*FakeClientProcessor
+		transports []*transport.TCP
+	)
+
+	newTransport := func(i int) *transport.TCP {
+		return &transport.TCP{
+			NodeID:          uint64(i),
+			Peers:           peers,
+			Node:            nodes[i],
+			ClientProcessor: processors[i],
+			Logger:          mirbft.ConsoleErrorLogger,
+			MinBackoff:      10 * time.Millisecond,
+			MaxBackoff:      100 * time.Millisecond,
+		}
+	}
+
+	BeforeEach(func() {
+		peers = map[uint64]string{}
+		listeners = make([]net.Listener, 2)
+		nodes = make([]*FakeNode, 2)
+		processors = make([]*FakeClientProcessor, 2)
+		transports = make([]*transport.TCP, 2)
+		for i := range listeners {
+			var err error
+			listeners[i], err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			peers[uint64(i)] = listeners[i].Addr().String()
+			nodes[i] = &FakeNode{StepC: make(chan SourceMsg, 10)}
+			processors[i] = &FakeClientProcessor{ForwardC: make(chan SourceMsg, 10)}
+		}
+
+		for i := range transports {
+			transports[i] = newTransport(i)
+		}
+	})
+
+	AfterEach(func() {
+		for _, t := range transports {
+			t.Stop()
+		}
+	})
+
+	It("delivers messages in order, stepped from the sender", func() {
+		for i, t := range transports {
+			Expect(t.Start(listeners[i])).To(Succeed())
+		}
+
+		for i := uint64(0); i < 5; i++ {
+			transports[0].Send(1, suspectMsg(i))
+		}
+
+		for i := uint64(0); i < 5; i++ {
+			var sourceMsg SourceMsg
+			Eventually(nodes[1].StepC).Should(Receive(&sourceMsg))
+			Expect(sourceMsg.Source).To(Equal(uint64(0)))
+			Expect(sourceMsg.Msg.Type.(*msgs.Msg_Suspect).Suspect.Epoch).To(Equal(i))
+		}
+	})
+
+	It("delivers broadcast messages in order with sent messages", func() {
+		for i, t := range transports {
+			Expect(t.Start(listeners[i])).To(Succeed())
+		}
+
+		var link mirbft.BroadcastLink = transports[0]
+		for i := uint64(0); i < 4; i++ {
+			if i%2 == 0 {
+				link.Send(1, suspectMsg(i))
+				continue
+			}
+
+			msgBytes, err := proto.Marshal(suspectMsg(i))
+			Expect(err).NotTo(HaveOccurred())
+			link.Broadcast([]uint64{1, 7}, msgBytes)
+		}
+
+		for i := uint64(0); i < 4; i++ {
+			var sourceMsg SourceMsg
+			Eventually(nodes[1].StepC).Should(Receive(&sourceMsg))
+			Expect(sourceMsg.Source).To(Equal(uint64(0)))
+			Expect(sourceMsg.Msg.Type.(*msgs.Msg_Suspect).Suspect.Epoch).To(Equal(i))
+		}
+	})
+
+	It("routes forwarded requests to the client processor", func() {
+		for i, t := range transports {
+			Expect(t.Start(listeners[i])).To(Succeed())
+		}
+
+		transports[1].Send(0, &msgs.Msg{
+			Type: &msgs.Msg_ForwardRequest{
+				ForwardRequest: &msgs.ForwardRequest{
+					RequestAck: &msgs.RequestAck{
+						ClientId: 3,
+						ReqNo:    4,
+					},
+					RequestData: []byte("data"),
+				},
+			},
+		})
+
+		var sourceMsg SourceMsg
+		Eventually(processors[0].ForwardC).Should(Receive(&sourceMsg))
+		Expect(sourceMsg.Source).To(Equal(uint64(1)))
+		Expect(nodes[0].StepC).NotTo(Receive())
+	})
+
+	It("discards malformed messages", func() {
+		for i, t := range transports {
+			Expect(t.Start(listeners[i])).To(Succeed())
+		}
+
+		transports[0].Send(1, &msgs.Msg{
+			Type: &msgs.Msg_Prepare{
+				Prepare: &msgs.Prepare{
+					SeqNo: 0,
+				},
+			},
+		})
+		transports[0].Send(1, suspectMsg(3))
+
+		var sourceMsg SourceMsg
+		Eventually(nodes[1].StepC).Should(Receive(&sourceMsg))
+		Expect(sourceMsg.Msg.Type.(*msgs.Msg_Suspect).Suspect.Epoch).To(Equal(uint64(3)))
+	})
+
+	It("reconnects when a peer restarts", func() {
+		for i, t := range transports {
+			Expect(t.Start(listeners[i])).To(Succeed())
+		}
+
+		transports[0].Send(1, suspectMsg(1))
+		Eventually(nodes[1].StepC).Should(Receive())
+
+		transports[1].Stop()
+
+		listener, err := net.Listen("tcp", peers[1])
+		Expect(err).NotTo(HaveOccurred())
+		transports[1] = newTransport(1)
+		Expect(transports[1].Start(listener)).To(Succeed())
+
+		Eventually(func() bool {
+			transports[0].Send(1, suspectMsg(2))
+			select {
+			case <-nodes[1].StepC:
+				return true
+			case <-time.After(20 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second).Should(BeTrue())
+	})
+
+	It("drops messages sent before it is started", func() {
+		logger := &RecordingLogger{}
+		transports[0].Logger = logger
+		transports[0].Send(1, suspectMsg(1))
+		msgBytes, err := proto.Marshal(suspectMsg(2))
+		Expect(err).NotTo(HaveOccurred())
+		transports[0].Broadcast([]uint64{1}, msgBytes)
+
+		Expect(logger.Entries()).To(Equal([]string{
+			"dropping message for unknown peer",
+			"dropping message for unknown peer",
+		}))
+
+		for i, t := range transports {
+			Expect(t.Start(listeners[i])).To(Succeed())
+		}
+		Consistently(nodes[1].StepC, 200*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("closes inbound connections which never send a node ID", func() {
+		Expect(transports[1].Start(listeners[1])).To(Succeed())
+
+		conn, err := net.Dial("tcp", peers[1])
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, err = conn.Read(make([]byte, 1))
+		Expect(err).To(Equal(io.EOF))
+	})
+})
@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// CertificateStore holds this node's TLS certificate and the pool of CAs
+// trusted to issue the certificates of peers.  The certificates are read
+// from PEM encoded files, and may be re-read at any time by calling Reload
+// (for instance, on SIGHUP, or when a certificate nears expiry).  Connections
+// established after a reload use the new certificates, existing connections
+// are unaffected.
+type CertificateStore struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	roots       *x509.CertPool
+}
+
+// Reload reads the certificate, key, and CA files from disk.  If any
+// cannot be loaded, an error is returned and the previously loaded
+// certificates remain in use.
+func (cs *CertificateStore) Reload() error {
+	certificate, err := tls.LoadX509KeyPair(cs.CertFile, cs.KeyFile)
+	if err != nil {
+		return errors.WithMessage(err, "could not load certificate and key")
+	}
+
+	caPEM, err := ioutil.ReadFile(cs.CAFile)
+	if err != nil {
+		return errors.WithMessage(err, "could not read CA file")
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return errors.Errorf("no certificates found in CA file %s", cs.CAFile)
+	}
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.certificate = &certificate
+	cs.roots = roots
+
+	return nil
+}
+
+func (cs *CertificateStore) getCertificate() (*tls.Certificate, error) {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+	if cs.certificate == nil {
+		return nil, errors.New("certificates have not been loaded")
+	}
+	return cs.certificate, nil
+}
+
+// verify checks that the certificate chain presented by a peer was issued
+// by one of the currently trusted CAs, and returns the leaf certificate.
+func (cs *CertificateStore) verify(rawCerts [][]byte) (*x509.Certificate, error) {
+	if len(rawCerts) == 0 {
+		return nil, errors.New("peer presented no certificate")
+	}
+
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return nil, errors.WithMessage(err, "could not parse peer certificate")
+		}
+		certs[i] = cert
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	cs.mutex.RLock()
+	roots := cs.roots
+	cs.mutex.RUnlock()
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not verify peer certificate")
+	}
+
+	return certs[0], nil
+}
+
+// CommonNameIdentity is the default TCP.NodeIdentity.  It expects the subject
+// common name of each node's certificate to be its decimal node ID.
+func CommonNameIdentity(cert *x509.Certificate) (uint64, error) {
+	id, err := strconv.ParseUint(cert.Subject.CommonName, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("certificate common name '%s' is not a node ID", cert.Subject.CommonName)
+	}
+	return id, nil
+}
+
+// clientTLSConfig returns the configuration for dialing the given peer, which
+// must present a certificate that maps to the peer's node ID.
+func (t *TCP) clientTLSConfig(dest uint64) *tls.Config {
+	return &tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return t.Certificates.getCertificate()
+		},
+		// We cannot use the standard verification, as the trusted
+		// roots may be reloaded, so we verify the chain ourselves.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			id, err := t.authenticate(rawCerts)
+			if err != nil {
+				return err
+			}
+
+			if id != dest {
+				return errors.Errorf("expected certificate for node %d, but got node %d", dest, id)
+			}
+
+			return nil
+		},
+	}
+}
+
+// serverTLSConfig returns the configuration for accepting connections, which
+// requires that the dialer present a certificate for a member of the network.
+func (t *TCP) serverTLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return t.Certificates.getCertificate()
+		},
+		ClientAuth: tls.RequireAnyClientCert,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			_, err := t.authenticate(rawCerts)
+			return err
+		},
+	}
+}
+
+// authenticate verifies the peer certificate chain and maps the leaf
+// certificate to the ID of a node in the current network configuration.
+func (t *TCP) authenticate(rawCerts [][]byte) (uint64, error) {
+	cert, err := t.Certificates.verify(rawCerts)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := t.NodeIdentity(cert)
+	if err != nil {
+		return 0, err
+	}
+
+	if !t.isMember(id) {
+		return 0, errors.Errorf("certificate maps to node %d which is not a member of the network", id)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,220 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/transport"
+)
+
+type CA struct {
+	Cert *x509.Certificate
+	Key  *ecdsa.PrivateKey
+	PEM  []byte
+}
+
+func newCA() *CA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+
+	return &CA{
+		Cert: cert,
+		Key:  key,
+		PEM:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// Issue returns the PEM encoded certificate and key for the named node.
+func (ca *CA) Issue(commonName string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, &key.PublicKey, ca.Key)
+	Expect(err).NotTo(HaveOccurred())
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+var _ = Describe("TCP with TLS", func() {
+	var (
+		tmpDir     string
+		ca         *CA
+		listeners  []net.Listener
+		peers      map[uint64]string
+		nodes      []*FakeNode
+		stores     []*transport.CertificateStore
+		transports []*transport.TCP
+	)
+
+	writeCerts := func(i int, issuer *CA, commonName string) {
+		cert, key := issuer.Issue(commonName)
+		Expect(ioutil.WriteFile(stores[i].CertFile, cert, 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(stores[i].KeyFile, key, 0600)).To(Succeed())
+	}
+
+	start := func() {
+		for i, t := range transports {
+			Expect(stores[i].Reload()).To(Succeed())
+			Expect(t.Start(listeners[i])).To(Succeed())
+		}
+	}
+
+	delivered := func(source, dest int) func() bool {
+		return func() bool {
+			transports[source].Send(uint64(dest), suspectMsg(1))
+			select {
+			case sourceMsg := <-nodes[dest].StepC:
+				Expect(sourceMsg.Source).To(Equal(uint64(source)))
+				return true
+			case <-time.After(20 * time.Millisecond):
+				return false
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "transport-tls")
+		Expect(err).NotTo(HaveOccurred())
+
+		ca = newCA()
+		caFile := filepath.Join(tmpDir, "ca.pem")
+		Expect(ioutil.WriteFile(caFile, ca.PEM, 0600)).To(Succeed())
+
+		// Node 2 never listens, but must be a known peer
+		peers = map[uint64]string{2: "127.0.0.1:1"}
+		listeners = make([]net.Listener, 2)
+		nodes = make([]*FakeNode, 2)
+		stores = make([]*transport.CertificateStore, 2)
+		transports = make([]*transport.TCP, 2)
+		for i := range listeners {
+			listeners[i], err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			peers[uint64(i)] = listeners[i].Addr().String()
+			nodes[i] = &FakeNode{StepC: make(chan SourceMsg, 10)}
+			stores[i] = &transport.CertificateStore{
+				CertFile: filepath.Join(tmpDir, fmt.Sprintf("node%d-cert.pem", i)),
+				KeyFile:  filepath.Join(tmpDir, fmt.Sprintf("node%d-key.pem", i)),
+				CAFile:   caFile,
+			}
+			writeCerts(i, ca, fmt.Sprintf("%d", i))
+		}
+
+		for i := range transports {
+			transports[i] = &transport.TCP{
+				NodeID:       uint64(i),
+				Peers:        peers,
+				Node:         nodes[i],
+				Logger:       mirbft.ConsoleErrorLogger,
+				MinBackoff:   10 * time.Millisecond,
+				MaxBackoff:   100 * time.Millisecond,
+				Certificates: stores[i],
+			}
+		}
+	})
+
+	AfterEach(func() {
+		for _, t := range transports {
+			t.Stop()
+		}
+		os.RemoveAll(tmpDir)
+	})
+
+	It("delivers messages from authenticated peers", func() {
+		start()
+		Eventually(delivered(0, 1), 5*time.Second).Should(BeTrue())
+		Eventually(delivered(1, 0), 5*time.Second).Should(BeTrue())
+	})
+
+	It("requires the certificates to be loaded before starting", func() {
+		Expect(transports[0].Start(listeners[0])).To(MatchError("certificates have not been loaded"))
+		listeners[0].Close()
+	})
+
+	It("rejects connections claiming to be another node", func() {
+		writeCerts(0, ca, "2")
+		start()
+		Consistently(delivered(0, 1), 500*time.Millisecond).Should(BeFalse())
+	})
+
+	It("rejects nodes which are no longer members of the network", func() {
+		start()
+		Eventually(delivered(0, 1), 5*time.Second).Should(BeTrue())
+
+		transports[1].SetMembers([]uint64{1, 2})
+		Consistently(delivered(0, 1), 500*time.Millisecond).Should(BeFalse())
+
+		transports[1].SetMembers([]uint64{0, 1, 2})
+		Eventually(delivered(0, 1), 5*time.Second).Should(BeTrue())
+	})
+
+	It("rejects certificates from untrusted CAs until reloaded", func() {
+		writeCerts(0, newCA(), "0")
+		start()
+		Consistently(delivered(0, 1), 500*time.Millisecond).Should(BeFalse())
+
+		writeCerts(0, ca, "0")
+		Expect(stores[0].Reload()).To(Succeed())
+		Eventually(delivered(0, 1), 5*time.Second).Should(BeTrue())
+	})
+})
+
+var _ = Describe("CommonNameIdentity", func() {
+	It("parses the common name as the node ID", func() {
+		id, err := transport.CommonNameIdentity(&x509.Certificate{Subject: pkix.Name{CommonName: "7"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(uint64(7)))
+
+		_, err = transport.CommonNameIdentity(&x509.Certificate{Subject: pkix.Name{CommonName: "node7"}})
+		Expect(err).To(MatchError("certificate common name 'node7' is not a node ID"))
+	})
+})
@@ -8,8 +8,11 @@ package mirbft
 
 import (
 	"hash"
+	"runtime"
+	"sync"
 
 	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/IBM/mirbft/pkg/pb/msgs"
 	"github.com/IBM/mirbft/pkg/pb/state"
@@ -26,6 +29,16 @@ type Link interface {
 	Send(dest uint64, msg *msgs.Msg)
 }
 
+// BroadcastLink is an optional extension of Link for links which can send
+// the same encoded message to many destinations.  When the Link of a
+// Processor implements BroadcastLink, each message is marshaled only once,
+// rather than once per destination.  The msgBytes must not be modified
+// by the link, as they may be shared.
+type BroadcastLink interface {
+	Link
+	Broadcast(dests []uint64, msgBytes []byte)
+}
+
 type App interface {
 	Apply(*msgs.QEntry) error
 	Snap(networkConfig *msgs.NetworkState_Config, clientsState []*msgs.NetworkState_Client) ([]byte, []*msgs.Reconfiguration, error)
@@ -44,9 +57,18 @@ type Processor struct {
 	Hasher Hasher
 	App    App
 	WAL    WAL
+
+	// Node, if set, supplies the client reconfigurations queued via
+	// Node.RegisterClient and Node.RetireClient, which are included
+	// in the result of the next checkpoint, and is notified of each
+	// committed batch, checkpoint, and state transfer to resolve the
+	// requests submitted via Node.Propose.
+	Node *Node
 }
 
 func (p *Processor) Process(actions *statemachine.ActionList) (*statemachine.EventList, error) {
+	p.Node.attachProcessor()
+
 	events := &statemachine.EventList{}
 	// First we'll handle everything that's not a network send
 	iter := actions.Iterator()
@@ -75,12 +97,15 @@ func (p *Processor) Process(actions *statemachine.ActionList) (*statemachine.Eve
 			if err := p.App.Apply(t.Commit.Batch); err != nil {
 				return nil, errors.WithMessage(err, "app failed to commit")
 			}
+			p.Node.committed(t.Commit.Batch)
 		case *state.Action_Checkpoint:
 			cp := t.Checkpoint
 			value, pendingReconf, err := p.App.Snap(cp.NetworkConfig, cp.ClientStates)
 			if err != nil {
 				return nil, errors.WithMessage(err, "app failed to generate snapshot")
 			}
+			pendingReconf = p.Node.checkpointReconfigurations(cp, pendingReconf)
+			p.Node.checkpointProposals(cp.ClientStates)
 			events.CheckpointResult(value, pendingReconf, cp)
 		case *state.Action_AllocatedRequest:
 			// We handle this in the client processor... for now
@@ -94,6 +119,7 @@ func (p *Processor) Process(actions *statemachine.ActionList) (*statemachine.Eve
 			if err != nil {
 				events.StateTransferFailed(stateTarget)
 			} else {
+				p.Node.checkpointProposals(state.Clients)
 				events.StateTransferComplete(state, stateTarget)
 			}
 		}
@@ -105,16 +131,24 @@ func (p *Processor) Process(actions *statemachine.ActionList) (*statemachine.Eve
 	}
 
 	// Now we transmit
+	broadcastLink, isBroadcast := p.Link.(BroadcastLink)
 	iter = actions.Iterator()
 	for action := iter.Next(); action != nil; action = iter.Next() {
 		switch t := action.Type.(type) {
 		case *state.Action_Send:
-			for _, replica := range t.Send.Targets {
-				if replica == p.NodeID {
-					events.Step(replica, t.Send.Msg)
-				} else {
-					p.Link.Send(replica, t.Send.Msg)
+			if !isBroadcast {
+				for _, replica := range t.Send.Targets {
+					if replica == p.NodeID {
+						events.Step(replica, t.Send.Msg)
+					} else {
+						p.Link.Send(replica, t.Send.Msg)
+					}
 				}
+				continue
+			}
+
+			if err := broadcast(p.NodeID, broadcastLink, t.Send, events); err != nil {
+				return nil, err
 			}
 		default:
 			// We've handled the other types already
@@ -123,3 +157,295 @@ func (p *Processor) Process(actions *statemachine.ActionList) (*statemachine.Eve
 
 	return events, nil
 }
+
+// broadcast marshals the message once and sends it to all targets other
+// than this node via the BroadcastLink.  If this node is a target, the
+// message is stepped locally.
+func broadcast(nodeID uint64, link BroadcastLink, send *state.ActionSend, events *statemachine.EventList) error {
+	dests := make([]uint64, 0, len(send.Targets))
+	for _, replica := range send.Targets {
+		if replica == nodeID {
+			events.Step(replica, send.Msg)
+		} else {
+			dests = append(dests, replica)
+		}
+	}
+
+	if len(dests) == 0 {
+		return nil
+	}
+
+	msgBytes, err := proto.Marshal(send.Msg)
+	if err != nil {
+		return errors.WithMessage(err, "could not marshal message for broadcast")
+	}
+
+	link.Broadcast(dests, msgBytes)
+
+	return nil
+}
+
+// ParallelProcessor performs the same actions as the Processor, but executes
+// independent work concurrently.  Hashes are computed on a pool of worker go
+// routines, and the WAL is written while the ClientProcessor (if supplied)
+// persists its requests.  Only once the WAL and request store have synced are
+// the commits, checkpoints, and state transfers applied, alongside the network
+// sends, which are performed with one go routine per destination so that the
+// per destination ordering of messages is preserved.  Because of this, the
+// Link must be safe for concurrent use.  If the Link implements BroadcastLink,
+// each message is instead marshaled once and broadcast in order, leaving the
+// fan out to the link.  The App is only ever invoked from a single go routine.
+type ParallelProcessor struct {
+	NodeID          uint64
+	Link            Link
+	Hasher          Hasher
+	App             App
+	WAL             WAL
+	ClientProcessor *ClientProcessor
+
+	// Node, if set, supplies the client reconfigurations queued via
+	// Node.RegisterClient and Node.RetireClient, which are included
+	// in the result of the next checkpoint, and is notified of each
+	// committed batch, checkpoint, and state transfer to resolve the
+	// requests submitted via Node.Propose.
+	Node *Node
+
+	// HashWorkers is the number of go routines to compute hashes with.
+	// If zero, runtime.NumCPU() go routines are used.
+	HashWorkers int
+}
+
+func (pp *ParallelProcessor) Process(actions *statemachine.ActionList) (*statemachine.EventList, error) {
+	pp.Node.attachProcessor()
+
+	var hashes []*state.ActionHashRequest
+	var sends []*state.ActionSend
+
+	iter := actions.Iterator()
+	for action := iter.Next(); action != nil; action = iter.Next() {
+		switch t := action.Type.(type) {
+		case *state.Action_Send:
+			sends = append(sends, t.Send)
+		case *state.Action_Hash:
+			hashes = append(hashes, t.Hash)
+		}
+	}
+
+	var hashWG sync.WaitGroup
+	hashResults := pp.hash(hashes, &hashWG)
+
+	var persistWG sync.WaitGroup
+	var walErr, clientErr, appErr error
+	var clientEvents, appEvents *statemachine.EventList
+
+	persistWG.Add(1)
+	go func() {
+		defer persistWG.Done()
+		walErr = pp.persist(actions)
+	}()
+
+	if pp.ClientProcessor != nil {
+		persistWG.Add(1)
+		go func() {
+			defer persistWG.Done()
+			clientEvents, clientErr = pp.ClientProcessor.Process(actions)
+		}()
+	}
+
+	persistWG.Wait()
+
+	if walErr != nil || clientErr != nil {
+		// Let the outstanding work complete before returning
+		hashWG.Wait()
+		if walErr != nil {
+			return nil, walErr
+		}
+		return nil, clientErr
+	}
+
+	// As for the Processor, commits, checkpoints, and state transfers are
+	// only applied once the WAL has synced, as the list may contain the
+	// entries they depend on, for instance the CEntry of a checkpoint, or
+	// the TEntry of a state transfer.  Otherwise, after a crash, the
+	// application state could be ahead of the WAL.
+	var appWG sync.WaitGroup
+	appWG.Add(1)
+	go func() {
+		defer appWG.Done()
+		appEvents, appErr = pp.apply(actions)
+	}()
+
+	// Everything is safely persisted, so now we may transmit
+	localEvents, sendErr := pp.send(sends)
+
+	hashWG.Wait()
+	appWG.Wait()
+
+	if sendErr != nil {
+		return nil, sendErr
+	}
+
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	events := &statemachine.EventList{}
+	for i, digest := range hashResults {
+		events.HashResult(digest, hashes[i].Origin)
+	}
+	events.PushBackList(appEvents)
+	if clientEvents != nil {
+		events.PushBackList(clientEvents)
+	}
+	events.PushBackList(localEvents)
+
+	return events, nil
+}
+
+// hash computes the digests for the hash requests on a pool of worker
+// go routines.  The returned slice is populated in the same order as
+// the requests once the wait group completes.
+func (pp *ParallelProcessor) hash(requests []*state.ActionHashRequest, wg *sync.WaitGroup) [][]byte {
+	results := make([][]byte, len(requests))
+	if len(requests) == 0 {
+		return results
+	}
+
+	workers := pp.HashWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(requests) {
+		workers = len(requests)
+	}
+
+	workC := make(chan int, len(requests))
+	for i := range requests {
+		workC <- i
+	}
+	close(workC)
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range workC {
+				h := pp.Hasher.New()
+				for _, data := range requests[j].Data {
+					h.Write(data)
+				}
+				results[j] = h.Sum(nil)
+			}
+		}()
+	}
+
+	return results
+}
+
+// persist writes and truncates the WAL in the order requested, then syncs it.
+func (pp *ParallelProcessor) persist(actions *statemachine.ActionList) error {
+	iter := actions.Iterator()
+	for action := iter.Next(); action != nil; action = iter.Next() {
+		switch t := action.Type.(type) {
+		case *state.Action_AppendWriteAhead:
+			write := t.AppendWriteAhead
+			if err := pp.WAL.Write(write.Index, write.Data); err != nil {
+				return errors.WithMessagef(err, "failed to write entry to WAL at index %d", write.Index)
+			}
+		case *state.Action_TruncateWriteAhead:
+			truncate := t.TruncateWriteAhead
+			if err := pp.WAL.Truncate(truncate.Index); err != nil {
+				return errors.WithMessagef(err, "failed to truncate WAL to index %d", truncate.Index)
+			}
+		}
+	}
+
+	if err := pp.WAL.Sync(); err != nil {
+		return errors.WithMessage(err, "failed to sync WAL")
+	}
+
+	return nil
+}
+
+// apply performs the commits, checkpoints, and state transfers in the order
+// requested.
+func (pp *ParallelProcessor) apply(actions *statemachine.ActionList) (*statemachine.EventList, error) {
+	events := &statemachine.EventList{}
+	iter := actions.Iterator()
+	for action := iter.Next(); action != nil; action = iter.Next() {
+		switch t := action.Type.(type) {
+		case *state.Action_Commit:
+			if err := pp.App.Apply(t.Commit.Batch); err != nil {
+				return nil, errors.WithMessage(err, "app failed to commit")
+			}
+			pp.Node.committed(t.Commit.Batch)
+		case *state.Action_Checkpoint:
+			cp := t.Checkpoint
+			value, pendingReconf, err := pp.App.Snap(cp.NetworkConfig, cp.ClientStates)
+			if err != nil {
+				return nil, errors.WithMessage(err, "app failed to generate snapshot")
+			}
+			pendingReconf = pp.Node.checkpointReconfigurations(cp, pendingReconf)
+			pp.Node.checkpointProposals(cp.ClientStates)
+			events.CheckpointResult(value, pendingReconf, cp)
+		case *state.Action_StateTransfer:
+			stateTarget := t.StateTransfer
+			state, err := pp.App.TransferTo(stateTarget.SeqNo, stateTarget.Value)
+			if err != nil {
+				events.StateTransferFailed(stateTarget)
+			} else {
+				pp.Node.checkpointProposals(state.Clients)
+				events.StateTransferComplete(state, stateTarget)
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// send transmits the messages to each remote destination from a separate
+// go routine, or via the BroadcastLink if supported, and returns the messages
+// destined for this node as events.
+func (pp *ParallelProcessor) send(sends []*state.ActionSend) (*statemachine.EventList, error) {
+	events := &statemachine.EventList{}
+
+	if broadcastLink, ok := pp.Link.(BroadcastLink); ok {
+		for _, send := range sends {
+			if err := broadcast(pp.NodeID, broadcastLink, send, events); err != nil {
+				return nil, err
+			}
+		}
+
+		return events, nil
+	}
+
+	byDest := map[uint64][]*msgs.Msg{}
+	var dests []uint64
+	for _, send := range sends {
+		for _, replica := range send.Targets {
+			if replica == pp.NodeID {
+				events.Step(replica, send.Msg)
+				continue
+			}
+
+			if _, ok := byDest[replica]; !ok {
+				dests = append(dests, replica)
+			}
+			byDest[replica] = append(byDest[replica], send.Msg)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(dests))
+	for _, dest := range dests {
+		go func(dest uint64, queue []*msgs.Msg) {
+			defer wg.Done()
+			for _, msg := range queue {
+				pp.Link.Send(dest, msg)
+			}
+		}(dest, byDest[dest])
+	}
+	wg.Wait()
+
+	return events, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirbft_test
+
+import (
+	"crypto"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/statemachine"
+)
+
+// SyncTrackingWAL records whether the entries written have been synced.
+type SyncTrackingWAL struct {
+	mutex  sync.Mutex
+	synced bool
+}
+
+func (stw *SyncTrackingWAL) Write(uint64, *msgs.Persistent) error {
+	stw.mutex.Lock()
+	defer stw.mutex.Unlock()
+	stw.synced = false
+	return nil
+}
+
+func (stw *SyncTrackingWAL) Truncate(uint64) error { return nil }
+
+func (stw *SyncTrackingWAL) Sync() error {
+	stw.mutex.Lock()
+	defer stw.mutex.Unlock()
+	stw.synced = true
+	return nil
+}
+
+func (stw *SyncTrackingWAL) Synced() bool {
+	stw.mutex.Lock()
+	defer stw.mutex.Unlock()
+	return stw.synced
+}
+
+// SnapRecordingApp records whether the WAL had synced when each snapshot
+// was taken.
+type SnapRecordingApp struct {
+	*FakeApp
+	WAL         *SyncTrackingWAL
+	SnapsSynced []bool
+}
+
+func (sra *SnapRecordingApp) Snap(networkConfig *msgs.NetworkState_Config, clientStates []*msgs.NetworkState_Client) ([]byte, []*msgs.Reconfiguration, error) {
+	sra.SnapsSynced = append(sra.SnapsSynced, sra.WAL.Synced())
+	return sra.FakeApp.Snap(networkConfig, clientStates)
+}
+
+var _ = Describe("ParallelProcessor", func() {
+	It("applies checkpoints only once the WAL has synced", func() {
+		wal := &SyncTrackingWAL{}
+		app := &SnapRecordingApp{
+			FakeApp: &FakeApp{},
+			WAL:     wal,
+		}
+		pp := &mirbft.ParallelProcessor{
+			Hasher: crypto.SHA256,
+			App:    app,
+			WAL:    wal,
+		}
+
+		actions := (&statemachine.ActionList{}).
+			Persist(1, &msgs.Persistent{
+				Type: &msgs.Persistent_CEntry{
+					CEntry: &msgs.CEntry{SeqNo: 5},
+				},
+			}).
+			Checkpoint(5, &msgs.NetworkState_Config{}, nil)
+
+		for i := 0; i < 20; i++ {
+			_, err := pp.Process(actions)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(app.SnapsSynced).To(HaveLen(20))
+		Expect(app.SnapsSynced).NotTo(ContainElement(false))
+	})
+})
@@ -0,0 +1,316 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirbft
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/statemachine"
+)
+
+// ErrCommitNotObserved is returned by Proposal.Wait when a checkpoint or state
+// transfer shows the request number as committed, but this node never applied
+// the batch it committed in, for instance because the node transferred state
+// past it.  Whether the proposed request, or some other request, committed for
+// the request number is unknown.
+var ErrCommitNotObserved = errors.New("request number committed without the batch being applied by this node")
+
+// Proposal tracks a request submitted via Node.Propose.  It is resolved once
+// the request commits, once a checkpoint or state transfer shows that its
+// commit will never be observed, or once the node stops.
+type Proposal struct {
+	// ClientID and ReqNo identify the proposed request.
+	ClientID uint64
+	ReqNo    uint64
+
+	digest []byte
+	doneC  chan struct{}
+	batch  *msgs.QEntry
+	err    error
+}
+
+func newProposal(clientID, reqNo uint64, digest []byte) *Proposal {
+	return &Proposal{
+		ClientID: clientID,
+		ReqNo:    reqNo,
+		digest:   digest,
+		doneC:    make(chan struct{}),
+	}
+}
+
+// Done returns a channel which is closed once the request has
+// committed, or the proposal has failed.
+func (p *Proposal) Done() <-chan struct{} {
+	return p.doneC
+}
+
+// Wait blocks until the request commits, and returns the sequence number
+// and the batch in which it committed.  If a different request committed
+// for the request number, if the commit was not observed by this node (see
+// ErrCommitNotObserved), if the client was removed, if the node stopped
+// before the request committed, or if the context ends, an error is returned.
+func (p *Proposal) Wait(ctx context.Context) (uint64, *msgs.QEntry, error) {
+	select {
+	case <-p.doneC:
+		if p.err != nil {
+			return 0, nil, p.err
+		}
+		return p.batch.SeqNo, p.batch, nil
+	case <-ctx.Done():
+		return 0, nil, ctx.Err()
+	}
+}
+
+func (p *Proposal) resolve(batch *msgs.QEntry, err error) {
+	p.batch = batch
+	p.err = err
+	close(p.doneC)
+}
+
+type proposalKey struct {
+	clientID uint64
+	reqNo    uint64
+}
+
+// proposals holds the outstanding proposals of a node.  They are resolved
+// as the processors apply committed batches, or failed at checkpoints and
+// state transfers, or when the node exits.
+type proposals struct {
+	mutex   sync.Mutex
+	pending map[proposalKey][]*Proposal
+	exitErr error
+
+	// attached is set once a processor configured with the node has
+	// processed actions, or the node has been Run with such a processor.
+	// Until then, nothing would resolve a proposal, so none are accepted.
+	attached bool
+
+	// lastClients are the IDs of the clients of the previous checkpoint
+	// or state transfer, so that removed clients may be detected.
+	lastClients map[uint64]struct{}
+}
+
+func (ps *proposals) add(p *Proposal) error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if ps.exitErr != nil {
+		return ps.exitErr
+	}
+
+	if !ps.attached {
+		return errors.New("no processor is configured with this node to resolve proposals")
+	}
+
+	if ps.pending == nil {
+		ps.pending = map[proposalKey][]*Proposal{}
+	}
+
+	key := proposalKey{clientID: p.ClientID, reqNo: p.ReqNo}
+	ps.pending[key] = append(ps.pending[key], p)
+	return nil
+}
+
+func (ps *proposals) attach() {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.attached = true
+}
+
+func (ps *proposals) remove(p *Proposal) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	key := proposalKey{clientID: p.ClientID, reqNo: p.ReqNo}
+	remaining := ps.pending[key][:0]
+	for _, op := range ps.pending[key] {
+		if op != p {
+			remaining = append(remaining, op)
+		}
+	}
+
+	if len(remaining) == 0 {
+		delete(ps.pending, key)
+		return
+	}
+	ps.pending[key] = remaining
+}
+
+// committed resolves the proposals for each request of the batch.
+func (ps *proposals) committed(batch *msgs.QEntry) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	for _, ack := range batch.Requests {
+		key := proposalKey{clientID: ack.ClientId, reqNo: ack.ReqNo}
+		for _, p := range ps.pending[key] {
+			if bytes.Equal(p.digest, ack.Digest) {
+				p.resolve(batch, nil)
+				continue
+			}
+
+			p.resolve(nil, errors.Errorf("request %d.%d committed with digest %x rather than the proposed digest %x", ack.ClientId, ack.ReqNo, ack.Digest, p.digest))
+		}
+		delete(ps.pending, key)
+	}
+}
+
+// checkpoint fails the proposals which can no longer be resolved by a
+// committed batch, given the client states of a checkpoint or state transfer.
+// These are the proposals whose request number the client states show as
+// committed, as the processors apply commits before the checkpoints which
+// follow them, and the proposals of clients which were present in the
+// previous client states, but have since been removed.
+func (ps *proposals) checkpoint(clientStates []*msgs.NetworkState_Client) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	clients := make(map[uint64]*msgs.NetworkState_Client, len(clientStates))
+	for _, clientState := range clientStates {
+		clients[clientState.Id] = clientState
+	}
+
+	for key, pending := range ps.pending {
+		var err error
+		clientState, ok := clients[key.clientID]
+		if ok {
+			if !statemachine.IsCommitted(key.reqNo, clientState) {
+				continue
+			}
+			err = ErrCommitNotObserved
+		} else {
+			if _, ok := ps.lastClients[key.clientID]; !ok {
+				// The client may not have been added yet
+				continue
+			}
+			err = errors.Errorf("client %d was removed before request %d committed", key.clientID, key.reqNo)
+		}
+
+		for _, p := range pending {
+			p.resolve(nil, err)
+		}
+		delete(ps.pending, key)
+	}
+
+	ps.lastClients = make(map[uint64]struct{}, len(clientStates))
+	for _, clientState := range clientStates {
+		ps.lastClients[clientState.Id] = struct{}{}
+	}
+}
+
+// failOnExit waits for the node to exit, then fails any outstanding
+// proposals, and any proposals made subsequently, with the exit error.
+func (ps *proposals) failOnExit(errC <-chan struct{}, exitErr func() error) {
+	<-errC
+	err := exitErr()
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.exitErr = err
+	for _, pending := range ps.pending {
+		for _, p := range pending {
+			p.resolve(nil, err)
+		}
+	}
+	ps.pending = nil
+}
+
+// Propose persists the request data for the given client and request number
+// via the node's ClientProcessor, making it available to the state machine,
+// and returns a Proposal which resolves once the request commits.  As with
+// Client.Propose, request numbers must be proposed in order.  If the state
+// machine has not yet allocated the client (for instance, because the node has
+// only just started), Propose retries until the context ends.  The returned
+// Proposal is only resolved as the node's processor applies committed batches,
+// checkpoints, and state transfers, so the Processor or ParallelProcessor
+// must be configured with this node, and the results of the ClientProcessor's
+// ClientWork must be injected into the node as usual, for instance, by Run.
+// If no such processor has processed the node's actions by the time the
+// client is allocated, Propose fails rather than return a Proposal which
+// would never resolve.
+func (n *Node) Propose(ctx context.Context, clientID, reqNo uint64, data []byte) (*Proposal, error) {
+	if n.ClientProcessor == nil {
+		return nil, errors.New("node has no client processor to propose with")
+	}
+
+	client := n.ClientProcessor.Client(clientID)
+	for {
+		_, err := client.NextReqNo()
+		if err == nil {
+			break
+		}
+
+		if err != ErrClientNotExist {
+			return nil, err
+		}
+
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-n.Err():
+			return nil, n.s.getExitErr()
+		}
+	}
+
+	h := n.ClientProcessor.Hasher.New()
+	h.Write(data)
+	proposal := newProposal(clientID, reqNo, h.Sum(nil))
+
+	// We register the proposal before proposing, so that we
+	// cannot miss the commit.
+	if err := n.proposals.add(proposal); err != nil {
+		return nil, err
+	}
+
+	if err := client.Propose(reqNo, data); err != nil {
+		n.proposals.remove(proposal)
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+// attachProcessor is invoked by the processors configured with the node,
+// and by Run, to record that proposals will be resolved.  A nil node has
+// no proposals to resolve.
+func (n *Node) attachProcessor() {
+	if n == nil {
+		return
+	}
+
+	n.proposals.attach()
+}
+
+// committed is invoked by the processors for each batch they apply, see
+// proposals.committed.  A nil node has no outstanding proposals.
+func (n *Node) committed(batch *msgs.QEntry) {
+	if n == nil {
+		return
+	}
+
+	n.proposals.committed(batch)
+}
+
+// checkpointProposals is invoked by the processors with the client states of
+// each checkpoint, and of each completed state transfer, see
+// proposals.checkpoint.  A nil node has no outstanding proposals.
+func (n *Node) checkpointProposals(clientStates []*msgs.NetworkState_Client) {
+	if n == nil {
+		return
+	}
+
+	n.proposals.checkpoint(clientStates)
+}
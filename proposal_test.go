@@ -0,0 +1,221 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirbft_test
+
+import (
+	"context"
+	"crypto"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/reqstore"
+	"github.com/IBM/mirbft/pkg/statemachine"
+)
+
+var _ = Describe("Node.Propose", func() {
+	var (
+		node            *mirbft.Node
+		reqStore        *reqstore.Store
+		clientProcessor *mirbft.ClientProcessor
+		processor       *mirbft.Processor
+	)
+
+	BeforeEach(func() {
+		var err error
+		node, err = mirbft.StartNewNode(&mirbft.Config{
+			ID:                   0,
+			Logger:               mirbft.ConsoleErrorLogger,
+			BatchSize:            1,
+			HeartbeatTicks:       2,
+			SuspectTicks:         4,
+			NewEpochTimeoutTicks: 8,
+			BufferSize:           500,
+		}, mirbft.StandardInitialNetworkState(1, 1), []byte("fake-application-state"))
+		Expect(err).NotTo(HaveOccurred())
+
+		reqStore, err = reqstore.Open("")
+		Expect(err).NotTo(HaveOccurred())
+
+		clientProcessor = &mirbft.ClientProcessor{
+			RequestStore: reqStore,
+			Hasher:       crypto.SHA256,
+		}
+		node.ClientProcessor = clientProcessor
+
+		processor = &mirbft.Processor{
+			Hasher: crypto.SHA256,
+			App:    &FakeApp{CommitC: make(chan *msgs.QEntry, 1)},
+			WAL:    NopWAL{},
+			Node:   node,
+		}
+	})
+
+	AfterEach(func() {
+		node.Stop()
+		reqStore.Close()
+	})
+
+	allocate := func(clientID, reqNo uint64) {
+		actions := (&statemachine.ActionList{}).AllocateRequest(clientID, reqNo)
+		_, err := processor.Process(actions)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = clientProcessor.Process(actions)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	commit := func(seqNo uint64, acks ...*msgs.RequestAck) *msgs.QEntry {
+		batch := &msgs.QEntry{
+			SeqNo:    seqNo,
+			Requests: acks,
+		}
+		_, err := processor.Process((&statemachine.ActionList{}).Commit(batch))
+		Expect(err).NotTo(HaveOccurred())
+		return batch
+	}
+
+	It("resolves with the batch the request commits in", func() {
+		allocate(0, 0)
+
+		proposal, err := node.Propose(context.Background(), 0, 0, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proposal.Done()).NotTo(BeClosed())
+
+		batch := commit(3, &msgs.RequestAck{
+			ClientId: 0,
+			ReqNo:    0,
+			Digest:   sha256Digest([]byte("data")),
+		})
+
+		seqNo, committed, err := proposal.Wait(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(seqNo).To(Equal(uint64(3)))
+		Expect(committed).To(Equal(batch))
+	})
+
+	It("fails if another request commits for the request number", func() {
+		allocate(0, 0)
+
+		proposal, err := node.Propose(context.Background(), 0, 0, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+
+		commit(3, &msgs.RequestAck{
+			ClientId: 0,
+			ReqNo:    0,
+		})
+
+		_, _, err = proposal.Wait(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("committed with digest")))
+	})
+
+	It("fails if the node stops before the request commits", func() {
+		allocate(0, 0)
+
+		proposal, err := node.Propose(context.Background(), 0, 0, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+
+		node.Stop()
+
+		_, _, err = proposal.Wait(context.Background())
+		Expect(err).To(Equal(mirbft.ErrStopped))
+
+		_, err = node.Propose(context.Background(), 0, 1, []byte("more-data"))
+		Expect(err).To(Equal(mirbft.ErrStopped))
+	})
+
+	It("fails once a checkpoint shows the request number committed", func() {
+		allocate(0, 0)
+
+		proposal, err := node.Propose(context.Background(), 0, 0, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = processor.Process((&statemachine.ActionList{}).Checkpoint(5, &msgs.NetworkState_Config{}, []*msgs.NetworkState_Client{
+			{Id: 0, Width: 100, LowWatermark: 1},
+		}))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, err = proposal.Wait(context.Background())
+		Expect(err).To(Equal(mirbft.ErrCommitNotObserved))
+	})
+
+	It("fails once its client is removed", func() {
+		allocate(0, 0)
+
+		proposal, err := node.Propose(context.Background(), 0, 0, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = processor.Process((&statemachine.ActionList{}).Checkpoint(5, &msgs.NetworkState_Config{}, []*msgs.NetworkState_Client{
+			{Id: 0, Width: 100},
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proposal.Done()).NotTo(BeClosed())
+
+		_, err = processor.Process((&statemachine.ActionList{}).Checkpoint(10, &msgs.NetworkState_Config{}, nil))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, err = proposal.Wait(context.Background())
+		Expect(err).To(MatchError("client 0 was removed before request 0 committed"))
+	})
+
+	It("fails once a state transfer shows the request number committed", func() {
+		processor.App = &TransferApp{
+			FakeApp: &FakeApp{},
+			NetworkState: &msgs.NetworkState{
+				Config: &msgs.NetworkState_Config{},
+				Clients: []*msgs.NetworkState_Client{
+					{Id: 0, Width: 100, LowWatermark: 1},
+				},
+			},
+		}
+
+		allocate(0, 0)
+
+		proposal, err := node.Propose(context.Background(), 0, 0, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = processor.Process((&statemachine.ActionList{}).StateTransfer(20, []byte("value")))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, err = proposal.Wait(context.Background())
+		Expect(err).To(Equal(mirbft.ErrCommitNotObserved))
+	})
+
+	It("fails if no processor configured with the node has processed its actions", func() {
+		processor.Node = nil
+		allocate(0, 0)
+
+		_, err := node.Propose(context.Background(), 0, 0, []byte("data"))
+		Expect(err).To(MatchError("no processor is configured with this node to resolve proposals"))
+
+		processor.Node = node
+		allocate(0, 1)
+
+		_, err = node.Propose(context.Background(), 0, 0, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("waits for the client to be allocated until the context ends", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := node.Propose(ctx, 0, 0, []byte("data"))
+		Expect(err).To(Equal(context.DeadlineExceeded))
+	})
+})
+
+// TransferApp is a FakeApp which transfers to the given network state.
+type TransferApp struct {
+	*FakeApp
+	NetworkState *msgs.NetworkState
+}
+
+func (ta *TransferApp) TransferTo(seqNo uint64, snap []byte) (*msgs.NetworkState, error) {
+	return ta.NetworkState, nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirbft
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/pb/state"
+)
+
+// ClientReconfiguration tracks a client registration or retirement queued
+// via Node.RegisterClient or Node.RetireClient.  It is resolved once the
+// client has been added to (or removed from) the network state of a
+// checkpoint, or once the reconfiguration is rejected.
+type ClientReconfiguration struct {
+	// ClientID is the ID of the client being registered or retired.
+	ClientID uint64
+
+	reconfiguration *msgs.Reconfiguration
+	doneC           chan struct{}
+	seqNo           uint64
+	err             error
+}
+
+func newClientReconfiguration(clientID uint64, reconfiguration *msgs.Reconfiguration) *ClientReconfiguration {
+	return &ClientReconfiguration{
+		ClientID:        clientID,
+		reconfiguration: reconfiguration,
+		doneC:           make(chan struct{}),
+	}
+}
+
+// Done returns a channel which is closed once the reconfiguration
+// has taken effect or has been rejected.
+func (cr *ClientReconfiguration) Done() <-chan struct{} {
+	return cr.doneC
+}
+
+// Wait blocks until the reconfiguration has taken effect, and returns the
+// sequence number of the first checkpoint whose network state reflects it.
+// If the reconfiguration was rejected, or the context ends, an error is returned.
+func (cr *ClientReconfiguration) Wait(ctx context.Context) (uint64, error) {
+	select {
+	case <-cr.doneC:
+		return cr.seqNo, cr.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (cr *ClientReconfiguration) isRegistration() bool {
+	_, ok := cr.reconfiguration.Type.(*msgs.Reconfiguration_NewClient_)
+	return ok
+}
+
+func (cr *ClientReconfiguration) resolve(seqNo uint64, err error) {
+	cr.seqNo = seqNo
+	cr.err = err
+	close(cr.doneC)
+}
+
+// reconfigurations holds the client reconfigurations queued at a node.  They
+// are included in the result of the next checkpoint the processor computes,
+// and resolved once a later checkpoint's client states reflect them.
+type reconfigurations struct {
+	mutex     sync.Mutex
+	queued    []*ClientReconfiguration
+	submitted []*ClientReconfiguration
+}
+
+func (r *reconfigurations) enqueue(cr *ClientReconfiguration) *ClientReconfiguration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.queued = append(r.queued, cr)
+	return cr
+}
+
+// checkpoint resolves the submitted reconfigurations which the checkpoint's
+// client states reflect, then appends the queued reconfigurations to those
+// pending from the application.  Queued reconfigurations which conflict with
+// the client states, or with another queued reconfiguration, are rejected.
+func (r *reconfigurations) checkpoint(cp *state.ActionCheckpoint, pending []*msgs.Reconfiguration) []*msgs.Reconfiguration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	clients := map[uint64]struct{}{}
+	for _, clientState := range cp.ClientStates {
+		clients[clientState.Id] = struct{}{}
+	}
+
+	submitted := r.submitted[:0]
+	for _, cr := range r.submitted {
+		_, ok := clients[cr.ClientID]
+		if ok != cr.isRegistration() {
+			// Not yet reflected in the network state
+			submitted = append(submitted, cr)
+			continue
+		}
+		cr.resolve(cp.SeqNo, nil)
+	}
+	r.submitted = submitted
+
+	for _, cr := range r.queued {
+		_, ok := clients[cr.ClientID]
+		switch {
+		case cr.isRegistration() && ok:
+			cr.resolve(0, errors.Errorf("client %d already exists", cr.ClientID))
+			continue
+		case !cr.isRegistration() && !ok:
+			cr.resolve(0, errors.Errorf("client %d does not exist", cr.ClientID))
+			continue
+		}
+
+		if cr.isRegistration() {
+			clients[cr.ClientID] = struct{}{}
+		} else {
+			delete(clients, cr.ClientID)
+		}
+
+		pending = append(pending, cr.reconfiguration)
+		r.submitted = append(r.submitted, cr)
+	}
+	r.queued = nil
+
+	return pending
+}
+
+// RegisterClient queues the addition of a client with the given request
+// window width to the network.  The reconfiguration is included in the result
+// of the next checkpoint processed by a processor configured with this node,
+// and the client becomes active at the checkpoint following that one.  As all
+// replicas must agree on the network state, RegisterClient must be invoked
+// identically at every replica with respect to the commit order, for instance,
+// by the App while applying the committed request which registers the client.
+func (n *Node) RegisterClient(clientID uint64, width uint32) *ClientReconfiguration {
+	return n.reconfigurations.enqueue(newClientReconfiguration(clientID, &msgs.Reconfiguration{
+		Type: &msgs.Reconfiguration_NewClient_{
+			NewClient: &msgs.Reconfiguration_NewClient{
+				Id:    clientID,
+				Width: width,
+			},
+		},
+	}))
+}
+
+// RetireClient queues the removal of a client from the network.  Once the
+// removal takes effect, the requests of the client may no longer commit.  The
+// same requirements as for RegisterClient apply.
+func (n *Node) RetireClient(clientID uint64) *ClientReconfiguration {
+	return n.reconfigurations.enqueue(newClientReconfiguration(clientID, &msgs.Reconfiguration{
+		Type: &msgs.Reconfiguration_RemoveClient{
+			RemoveClient: clientID,
+		},
+	}))
+}
+
+// checkpointReconfigurations is invoked by the processors for each
+// checkpoint, see reconfigurations.checkpoint.  A nil node has no queued
+// reconfigurations.
+func (n *Node) checkpointReconfigurations(cp *state.ActionCheckpoint, pending []*msgs.Reconfiguration) []*msgs.Reconfiguration {
+	if n == nil {
+		return pending
+	}
+
+	return n.reconfigurations.checkpoint(cp, pending)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirbft_test
+
+import (
+	"context"
+	"crypto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/pb/state"
+	"github.com/IBM/mirbft/pkg/statemachine"
+)
+
+type NopWAL struct{}
+
+func (NopWAL) Write(uint64, *msgs.Persistent) error { return nil }
+func (NopWAL) Truncate(uint64) error                { return nil }
+func (NopWAL) Sync() error                          { return nil }
+
+var _ = Describe("Client reconfiguration", func() {
+	var (
+		node      *mirbft.Node
+		processor *mirbft.Processor
+	)
+
+	checkpoint := func(seqNo uint64, clientIDs ...uint64) []*msgs.Reconfiguration {
+		clientStates := make([]*msgs.NetworkState_Client, len(clientIDs))
+		for i, clientID := range clientIDs {
+			clientStates[i] = &msgs.NetworkState_Client{Id: clientID, Width: 100}
+		}
+
+		events, err := processor.Process((&statemachine.ActionList{}).Checkpoint(seqNo, &msgs.NetworkState_Config{}, clientStates))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events.Len()).To(Equal(1))
+		result := events.Iterator().Next().Type.(*state.Event_CheckpointResult).CheckpointResult
+		return result.NetworkState.PendingReconfigurations
+	}
+
+	BeforeEach(func() {
+		node = &mirbft.Node{}
+		processor = &mirbft.Processor{
+			Hasher: crypto.SHA256,
+			App:    &FakeApp{},
+			WAL:    NopWAL{},
+			Node:   node,
+		}
+	})
+
+	It("includes registrations in the next checkpoint and resolves once active", func() {
+		registration := node.RegisterClient(7, 50)
+
+		pending := checkpoint(10, 0)
+		Expect(pending).To(HaveLen(1))
+		Expect(pending[0].Type.(*msgs.Reconfiguration_NewClient_).NewClient).To(Equal(&msgs.Reconfiguration_NewClient{
+			Id:    7,
+			Width: 50,
+		}))
+		Consistently(registration.Done()).ShouldNot(BeClosed())
+
+		Expect(checkpoint(20, 0, 7)).To(BeEmpty())
+		seqNo, err := registration.Wait(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(seqNo).To(Equal(uint64(20)))
+	})
+
+	It("includes retirements in the next checkpoint and resolves once removed", func() {
+		retirement := node.RetireClient(0)
+
+		pending := checkpoint(10, 0, 1)
+		Expect(pending).To(HaveLen(1))
+		Expect(pending[0].Type.(*msgs.Reconfiguration_RemoveClient).RemoveClient).To(Equal(uint64(0)))
+
+		checkpoint(20, 0, 1)
+		Consistently(retirement.Done()).ShouldNot(BeClosed())
+
+		checkpoint(30, 1)
+		seqNo, err := retirement.Wait(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(seqNo).To(Equal(uint64(30)))
+	})
+
+	It("rejects registrations of existing clients", func() {
+		registration := node.RegisterClient(0, 50)
+		first := node.RegisterClient(7, 50)
+		duplicate := node.RegisterClient(7, 50)
+
+		Expect(checkpoint(10, 0)).To(HaveLen(1))
+
+		_, err := registration.Wait(context.Background())
+		Expect(err).To(MatchError("client 0 already exists"))
+
+		_, err = duplicate.Wait(context.Background())
+		Expect(err).To(MatchError("client 7 already exists"))
+
+		Expect(first.Done()).NotTo(BeClosed())
+	})
+
+	It("rejects retirements of unknown clients", func() {
+		retirement := node.RetireClient(3)
+
+		Expect(checkpoint(10, 0)).To(BeEmpty())
+
+		_, err := retirement.Wait(context.Background())
+		Expect(err).To(MatchError("client 3 does not exist"))
+	})
+})
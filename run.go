@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirbft
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/IBM/mirbft/pkg/statemachine"
+)
+
+// ActionProcessor performs the actions requested by the state machine,
+// returning the resulting events.  It is implemented by Processor,
+// ParallelProcessor, and ClientProcessor.
+type ActionProcessor interface {
+	Process(*statemachine.ActionList) (*statemachine.EventList, error)
+}
+
+// RunConfig configures the loop which Node.Run drives.
+type RunConfig struct {
+	// Processor performs the actions of the node, typically it is a Processor
+	// or ParallelProcessor.  If its Node is not set, Run sets it to the node,
+	// so that the processor resolves the node's proposals.
+	Processor ActionProcessor
+
+	// ClientProcessor, if set, performs the client actions of the node, and
+	// the events of its ClientWork are injected into the node.  If the
+	// Processor is a ParallelProcessor configured with a ClientProcessor, the
+	// client actions are only performed by the ParallelProcessor, and the
+	// events of its ClientProcessor's ClientWork are injected, so this need
+	// not be set, but if set, it must be the same ClientProcessor.
+	ClientProcessor *ClientProcessor
+
+	// TickInterval is the time which elapses between each tick of the
+	// state machine.  It must be positive.
+	TickInterval time.Duration
+}
+
+// Run drives the node until the context ends or the node stops.  Actions are
+// read from the node and performed by the processors, the resulting events,
+// any events of the client work, and a tick every TickInterval, are injected
+// into the node.  Each action list is processed completely, and its events
+// injected, before the next is read, so the ordering required by the state
+// machine is preserved.  Messages from other nodes must still be delivered via
+// Step, and forwarded requests via ClientProcessor.StepForwardRequest.
+//
+// If a processor fails, the node is stopped with the processor's error, so
+// that Err closes and Status reports the failure.  If the context ends, the
+// node is stopped.  In either case, Run returns once the node has stopped,
+// with the node's exit error, which is ErrStopped if the node stopped at the
+// caller's request.  While Run executes, the caller must not read from
+// Actions, nor from the ClientWork of the ClientProcessor.
+func (n *Node) Run(ctx context.Context, rc RunConfig) error {
+	if rc.Processor == nil {
+		return errors.New("run requires a processor")
+	}
+
+	if rc.TickInterval <= 0 {
+		return errors.Errorf("run requires a positive tick interval, got %v", rc.TickInterval)
+	}
+
+	switch p := rc.Processor.(type) {
+	case *Processor:
+		if p.Node == nil {
+			p.Node = n
+		}
+
+		if p.Node != n {
+			return errors.New("run requires the processor to be configured with this node")
+		}
+
+		n.attachProcessor()
+	case *ParallelProcessor:
+		if p.Node == nil {
+			p.Node = n
+		}
+
+		if p.Node != n {
+			return errors.New("run requires the processor to be configured with this node")
+		}
+
+		n.attachProcessor()
+	}
+
+	clientProcessor := rc.ClientProcessor
+	workProcessor := rc.ClientProcessor
+	if pp, ok := rc.Processor.(*ParallelProcessor); ok && pp.ClientProcessor != nil {
+		if clientProcessor != nil && clientProcessor != pp.ClientProcessor {
+			return errors.New("run requires the client processor to be the same as that of the parallel processor")
+		}
+
+		clientProcessor = nil
+		workProcessor = pp.ClientProcessor
+	}
+
+	var clientWork *ClientWork
+	if workProcessor != nil {
+		clientWork = &workProcessor.ClientWork
+	}
+
+	ticker := time.NewTicker(rc.TickInterval)
+	defer ticker.Stop()
+
+	for {
+		var clientWorkReadyC <-chan struct{}
+		if clientWork != nil {
+			clientWorkReadyC = clientWork.Ready()
+		}
+
+		events := &statemachine.EventList{}
+
+		select {
+		case actions := <-n.Actions():
+			results, err := rc.Processor.Process(actions)
+			if err != nil {
+				n.s.fail(errors.WithMessage(err, "processor failed"))
+				return n.s.getExitErr()
+			}
+			events.PushBackList(results)
+
+			if clientProcessor != nil {
+				results, err = clientProcessor.Process(actions)
+				if err != nil {
+					n.s.fail(errors.WithMessage(err, "client processor failed"))
+					return n.s.getExitErr()
+				}
+				events.PushBackList(results)
+			}
+		case <-clientWorkReadyC:
+			events.PushBackList(clientWork.Results())
+		case <-ticker.C:
+			events.TickElapsed()
+		case <-ctx.Done():
+			n.Stop()
+			return n.s.getExitErr()
+		case <-n.Err():
+			return n.s.getExitErr()
+		}
+
+		if events.Len() == 0 {
+			continue
+		}
+
+		if err := n.InjectEvents(events); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirbft_test
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM/mirbft"
+	"github.com/IBM/mirbft/pkg/pb/msgs"
+	"github.com/IBM/mirbft/pkg/reqstore"
+	"github.com/IBM/mirbft/pkg/statemachine"
+)
+
+type FailingProcessor struct{}
+
+func (FailingProcessor) Process(*statemachine.ActionList) (*statemachine.EventList, error) {
+	return nil, fmt.Errorf("fake-failure")
+}
+
+var _ = Describe("Node.Run", func() {
+	var (
+		node            *mirbft.Node
+		reqStore        *reqstore.Store
+		clientProcessor *mirbft.ClientProcessor
+		processor       *mirbft.Processor
+		ctx             context.Context
+		cancel          context.CancelFunc
+		runErrC         chan error
+	)
+
+	BeforeEach(func() {
+		var err error
+		node, err = mirbft.StartNewNode(&mirbft.Config{
+			ID:                   0,
+			Logger:               mirbft.ConsoleErrorLogger,
+			BatchSize:            1,
+			HeartbeatTicks:       2,
+			SuspectTicks:         4,
+			NewEpochTimeoutTicks: 8,
+			BufferSize:           500,
+		}, mirbft.StandardInitialNetworkState(1, 1), []byte("fake-application-state"))
+		Expect(err).NotTo(HaveOccurred())
+
+		reqStore, err = reqstore.Open("")
+		Expect(err).NotTo(HaveOccurred())
+
+		clientProcessor = &mirbft.ClientProcessor{
+			RequestStore: reqStore,
+			Hasher:       crypto.SHA256,
+		}
+		node.ClientProcessor = clientProcessor
+
+		processor = &mirbft.Processor{
+			Hasher: crypto.SHA256,
+			App:    &FakeApp{CommitC: make(chan *msgs.QEntry, 10)},
+			WAL:    NopWAL{},
+			Node:   node,
+		}
+
+		ctx, cancel = context.WithCancel(context.Background())
+		runErrC = make(chan error, 1)
+	})
+
+	AfterEach(func() {
+		cancel()
+		node.Stop()
+		reqStore.Close()
+	})
+
+	run := func(rc mirbft.RunConfig) {
+		node, ctx, runErrC := node, ctx, runErrC
+		go func() {
+			runErrC <- node.Run(ctx, rc)
+		}()
+	}
+
+	It("commits proposed requests", func() {
+		run(mirbft.RunConfig{
+			Processor:       processor,
+			ClientProcessor: clientProcessor,
+			TickInterval:    10 * time.Millisecond,
+		})
+
+		for i := uint64(0); i < 3; i++ {
+			proposal, err := node.Propose(ctx, 0, i, []byte(fmt.Sprintf("data-%d", i)))
+			Expect(err).NotTo(HaveOccurred())
+
+			waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Second)
+			_, batch, err := proposal.Wait(waitCtx)
+			waitCancel()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(batch.Requests).To(HaveLen(1))
+			Expect(batch.Requests[0].ReqNo).To(Equal(i))
+		}
+	})
+
+	It("drains the client work of a parallel processor's client processor", func() {
+		run(mirbft.RunConfig{
+			Processor: &mirbft.ParallelProcessor{
+				Hasher:          crypto.SHA256,
+				App:             &FakeApp{CommitC: make(chan *msgs.QEntry, 10)},
+				WAL:             NopWAL{},
+				ClientProcessor: clientProcessor,
+				Node:            node,
+			},
+			TickInterval: 10 * time.Millisecond,
+		})
+
+		proposal, err := node.Propose(ctx, 0, 0, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+
+		waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer waitCancel()
+		_, batch, err := proposal.Wait(waitCtx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(batch.Requests).To(HaveLen(1))
+	})
+
+	It("rejects a client processor which differs from the parallel processor's", func() {
+		err := node.Run(ctx, mirbft.RunConfig{
+			Processor: &mirbft.ParallelProcessor{
+				ClientProcessor: clientProcessor,
+			},
+			ClientProcessor: &mirbft.ClientProcessor{},
+			TickInterval:    10 * time.Millisecond,
+		})
+		Expect(err).To(MatchError("run requires the client processor to be the same as that of the parallel processor"))
+	})
+
+	It("configures a processor without a node with this node", func() {
+		processor.Node = nil
+		run(mirbft.RunConfig{
+			Processor:       processor,
+			ClientProcessor: clientProcessor,
+			TickInterval:    10 * time.Millisecond,
+		})
+
+		proposal, err := node.Propose(ctx, 0, 0, []byte("data"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processor.Node).To(Equal(node))
+
+		waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer waitCancel()
+		_, batch, err := proposal.Wait(waitCtx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(batch.Requests).To(HaveLen(1))
+	})
+
+	It("rejects a processor configured with another node", func() {
+		processor.Node = &mirbft.Node{}
+		err := node.Run(ctx, mirbft.RunConfig{
+			Processor:    processor,
+			TickInterval: 10 * time.Millisecond,
+		})
+		Expect(err).To(MatchError("run requires the processor to be configured with this node"))
+	})
+
+	It("stops the node when the context ends", func() {
+		run(mirbft.RunConfig{
+			Processor:       processor,
+			ClientProcessor: clientProcessor,
+			TickInterval:    10 * time.Millisecond,
+		})
+
+		cancel()
+		Eventually(runErrC).Should(Receive(Equal(mirbft.ErrStopped)))
+		Expect(node.Err()).To(BeClosed())
+	})
+
+	It("stops the node with the error of a failed processor", func() {
+		run(mirbft.RunConfig{
+			Processor:    FailingProcessor{},
+			TickInterval: 10 * time.Millisecond,
+		})
+
+		var err error
+		Eventually(runErrC).Should(Receive(&err))
+		Expect(err).To(MatchError("processor failed: fake-failure"))
+		Expect(node.Err()).To(BeClosed())
+
+		_, err = node.Status(context.Background())
+		Expect(err).To(MatchError("processor failed: fake-failure"))
+	})
+
+	It("rejects a non-positive tick interval", func() {
+		err := node.Run(ctx, mirbft.RunConfig{
+			Processor: processor,
+		})
+		Expect(err).To(MatchError("run requires a positive tick interval, got 0s"))
+	})
+})
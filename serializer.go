@@ -30,6 +30,7 @@ type serializer struct {
 	walStorage WALStorage
 
 	exitMutex  sync.Mutex
+	stopErr    error
 	exitErr    error
 	exitStatus *status.StateMachine
 }
@@ -50,16 +51,29 @@ func newSerializer(myConfig *Config, walStorage WALStorage) (*serializer, error)
 }
 
 func (s *serializer) stop() {
+	s.fail(ErrStopped)
+}
+
+// fail stops the serializer, and unless it has already been asked to stop,
+// records err as the exit error.
+func (s *serializer) fail(err error) {
 	s.exitMutex.Lock()
 	select {
 	case <-s.doneC:
 	default:
+		s.stopErr = err
 		close(s.doneC)
 	}
 	s.exitMutex.Unlock()
 	<-s.errC
 }
 
+func (s *serializer) getStopErr() error {
+	s.exitMutex.Lock()
+	defer s.exitMutex.Unlock()
+	return s.stopErr
+}
+
 func (s *serializer) getExitErr() error {
 	s.exitMutex.Lock()
 	defer s.exitMutex.Unlock()
@@ -79,7 +93,8 @@ func (la logAdapter) Log(level statemachine.LogLevel, msg string, args ...interf
 // of other go routines.
 func (s *serializer) run() (exitErr error) {
 	sm := &statemachine.StateMachine{
-		Logger: logAdapter{Logger: s.myConfig.Logger},
+		Logger:       logAdapter{Logger: s.myConfig.Logger},
+		LeaderPolicy: s.myConfig.LeaderPolicy,
 	}
 
 	defer func() {
@@ -117,6 +132,8 @@ func (s *serializer) run() (exitErr error) {
 			Initialize: &state.EventInitialParameters{
 				Id:                   s.myConfig.ID,
 				BatchSize:            s.myConfig.BatchSize,
+				MaxBatchBytes:        s.myConfig.MaxBatchBytes,
+				BatchTimeoutTicks:    s.myConfig.BatchTimeoutTicks,
 				HeartbeatTicks:       s.myConfig.HeartbeatTicks,
 				SuspectTicks:         s.myConfig.SuspectTicks,
 				NewEpochTimeoutTicks: s.myConfig.NewEpochTimeoutTicks,
@@ -184,7 +201,7 @@ func (s *serializer) run() (exitErr error) {
 			case <-s.doneC:
 			}
 		case <-s.doneC:
-			return ErrStopped
+			return s.getStopErr()
 		}
 
 		if err != nil {